@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber"
+)
+
+// watchedListener - Accepts connections that can tell when the client goes away in the middle of a request
+type watchedListener struct {
+	net.Listener
+}
+
+// Accept ...
+func (l watchedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &watchedConn{Conn: conn}, nil
+}
+
+// watchedConn - fasthttp doesn't read the connection while the handler runs, so a client that goes away goes
+// unnoticed until the response is written. Watching starts the read of the next request early, as net/http does,
+// and hands what it got to fasthttp when it reads again.
+type watchedConn struct {
+	net.Conn
+
+	mu sync.Mutex
+	// read is closed once the early read returns, it's nil when there's no early read to hand over
+	read chan struct{}
+	// gone is closed when the early read finds the connection closed
+	gone chan struct{}
+	b    [1]byte
+	n    int
+	err  error
+}
+
+// watch - Starts the early read, if it's not running yet, returning what's closed when the client goes away.
+// It must only be called while the request is handled, when fasthttp is done reading it.
+func (c *watchedConn) watch() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.read != nil {
+		return c.gone
+	}
+
+	// fasthttp sets the read deadline again before reading the next request, until then the handler
+	// may take as long as it needs.
+	if err := c.Conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil
+	}
+
+	c.read = make(chan struct{})
+	c.gone = make(chan struct{})
+
+	go c.readEarly(c.read, c.gone)
+
+	return c.gone
+}
+
+func (c *watchedConn) readEarly(read chan struct{}, gone chan struct{}) {
+	n, err := c.Conn.Read(c.b[:])
+
+	c.mu.Lock()
+	c.n, c.err = n, err
+	c.mu.Unlock()
+
+	if n == 0 && err != nil {
+		close(gone)
+	}
+
+	close(read)
+}
+
+// Read - Hands over the early read, when there's one, before reading the connection again
+func (c *watchedConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	read := c.read
+	c.mu.Unlock()
+
+	if read == nil || len(p) == 0 {
+		return c.Conn.Read(p)
+	}
+
+	<-read
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.read = nil
+
+	if c.n == 0 {
+		return 0, c.err
+	}
+
+	p[0] = c.b[0]
+
+	return 1, nil
+}
+
+// clientGone - What's closed when the client of the request closes its connection, nil when it can't be watched
+func clientGone(c *fiber.Ctx) <-chan struct{} {
+	conn, ok := c.Fasthttp.Conn().(*watchedConn)
+	if !ok {
+		return nil
+	}
+
+	return conn.watch()
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gofiber/fiber"
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	requestIDMaxLength = 128
+	requestTimeout     = time.Minute * 3
+
+	// tokenLocalsKey is where the JWT middleware leaves the validated token
+	tokenLocalsKey = "user"
+)
+
+// requestIDMiddleware - Identifies the request with the X-Request-ID sent by the client, or a new one,
+// and echoes it in the response.
+func (s Service) requestIDMiddleware(c *fiber.Ctx) {
+	requestID := c.Get(requestIDHeader)
+	if requestID == "" || len(requestID) > requestIDMaxLength {
+		requestID = newRequestID()
+		c.Fasthttp.Request.Header.Set(requestIDHeader, requestID)
+	}
+
+	c.Locals(infra.IDContextValueKey, requestID)
+	c.Set(requestIDHeader, requestID)
+
+	c.Next()
+}
+
+// requestContext - The handler context, carrying the request id for logs and errors. It's canceled when the client
+// goes away, or when the shutdown deadline expires, so the database stops working for nobody.
+func (s Service) requestContext(c *fiber.Ctx) (context.Context, context.CancelFunc) {
+	// fasthttp cancels the request context as soon as the shutdown starts, which would abort the requests
+	// we want to drain, so only the request id is taken from it. It's copied because fasthttp recycles
+	// the request context once the handler returns, and errors are logged after that.
+	ctx := context.WithValue(s.serving, infra.IDContextValueKey, c.Locals(infra.IDContextValueKey))
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+
+	if gone := clientGone(c); gone != nil {
+		go func() {
+			select {
+			case <-gone:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, cancel
+}
+
+// can - Guards a route, letting the request through only when the role in the token has the permission
+func (s Service) can(permission domain.Permission) func(*fiber.Ctx) {
+	const opName infra.OpName = "server.can"
+
+	return func(c *fiber.Ctx) {
+		role := domain.Role(tokenClaims(c).Role)
+
+		if !role.Can(permission) {
+			ctx, cancel := s.requestContext(c)
+			defer cancel()
+
+			s.in.Reporter.Report(errors.New(ctx, opName, "The role lacks the permission.", infra.KindForbidden, infra.Metadata{
+				"role":       role,
+				"permission": permission,
+				"path":       c.Path(),
+			}))
+
+			c.Status(403).JSON(map[string]interface{}{
+				"message": "You are not allowed to do this.",
+			})
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sessionMiddleware - Rejects the tokens of revoked sessions, and the ones issued before the sessions existed
+func (s Service) sessionMiddleware(c *fiber.Ctx) {
+	const opName infra.OpName = "server.sessionMiddleware"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	claims := tokenClaims(c)
+
+	sessionID, err := strconv.Atoi(claims.SessionID)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.KindUnauthorized, infra.Metadata{
+			"userID": claims.UserID,
+		}))
+
+		c.Status(401).JSON(map[string]interface{}{
+			"message": "The session has ended, log in again.",
+		})
+
+		return
+	}
+
+	active, sErr := s.in.AuthRepo.SessionActive(ctx, infra.ObjectID(sessionID))
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"sessionID": sessionID,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	if !active {
+		s.in.Reporter.Report(errors.New(ctx, opName, "The session was revoked.", infra.KindUnauthorized, infra.Metadata{
+			"sessionID": sessionID,
+			"userID":    claims.UserID,
+		}))
+
+		c.Status(401).JSON(map[string]interface{}{
+			"message": "The session has ended, log in again.",
+		})
+
+		return
+	}
+
+	c.Next()
+}
+
+// tokenClaims - The claims of the token validated by the JWT middleware, empty when there's none
+func tokenClaims(c *fiber.Ctx) infra.TokenClaims {
+	token, ok := c.Locals(tokenLocalsKey).(*jwt.Token)
+	if !ok {
+		return infra.TokenClaims{}
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return infra.TokenClaims{}
+	}
+
+	userID, _ := claims["userID"].(string)
+	role, _ := claims["role"].(string)
+	sessionID, _ := claims["sid"].(string)
+
+	return infra.TokenClaims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+
+	return hex.EncodeToString(id)
+}
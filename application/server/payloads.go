@@ -5,6 +5,10 @@ type loginPayload struct {
 	Password string `json:"password" validate:"required,min=3,max=100"`
 }
 
+type refreshTokenPayload struct {
+	RefreshToken string `json:"refreshToken" validate:"required,max=100"`
+}
+
 type customerPayload struct {
 	Name  string `json:"name" validate:"required,min=2,max=40"`
 	Phone string `json:"phone" validate:"max=11"`
@@ -13,6 +17,7 @@ type customerPayload struct {
 type candyPayload struct {
 	Name  string `json:"name" validate:"required,min=3,max=100"`
 	Price int    `json:"price" validate:"required,min=2"`
+	Cost  int    `json:"cost" validate:"min=0"`
 }
 
 type salePayload struct {
@@ -20,10 +25,176 @@ type salePayload struct {
 	CandyID       int    `json:"candyId" validate:"required,min=1"`
 	Status        string `json:"status" validate:"required,oneof=paid not_paid"`
 	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=money transfer scheduled"`
-	Date          string `json:"date" validate:"required"`
+	Date          string `json:"date" validate:"required,datetime=2006-01-02"`
 }
 
 type updateSalePayload struct {
 	Status        string `json:"status" validate:"required,oneof=paid not_paid"`
 	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=money transfer scheduled"`
 }
+
+type orderItemPayload struct {
+	CandyID  int `json:"candyId" validate:"required,min=1"`
+	Quantity int `json:"quantity" validate:"required,min=1"`
+	Discount int `json:"discount" validate:"min=0"`
+}
+
+type orderPayload struct {
+	CustomerID    int                `json:"customerId" validate:"required,min=1"`
+	Status        string             `json:"status" validate:"required,oneof=paid not_paid"`
+	PaymentMethod string             `json:"paymentMethod" validate:"required,oneof=money transfer scheduled"`
+	Date          string             `json:"date" validate:"required,datetime=2006-01-02"`
+	Items         []orderItemPayload `json:"items" validate:"required,min=1,dive"`
+}
+
+type updateOrderPayload struct {
+	Status        string `json:"status" validate:"required,oneof=paid not_paid"`
+	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=money transfer scheduled"`
+}
+
+type paymentPayload struct {
+	CustomerID    int    `json:"customerId" validate:"required,min=1"`
+	Amount        int    `json:"amount" validate:"required,min=1"`
+	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=money transfer"`
+	Date          string `json:"date" validate:"required,datetime=2006-01-02"`
+	Note          string `json:"note" validate:"max=200"`
+}
+
+type queryOptionsPayload struct {
+	Limit     int    `query:"limit" validate:"min=0,max=200"`
+	Offset    int    `query:"offset" validate:"min=0"`
+	Cursor    string `query:"cursor" validate:"max=512"`
+	Sort      string `query:"sort" validate:"max=20"`
+	Direction string `query:"direction" validate:"omitempty,oneof=asc desc"`
+	Search    string `query:"search" validate:"max=100"`
+}
+
+type salesFilterPayload struct {
+	queryOptionsPayload
+
+	From          string `json:"from" query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To            string `json:"to" query:"to" validate:"omitempty,datetime=2006-01-02"`
+	CustomerID    int    `json:"customerId" query:"customerId" validate:"min=0"`
+	CandyID       int    `json:"candyId" query:"candyId" validate:"min=0"`
+	Status        string `json:"status" query:"status" validate:"omitempty,oneof=paid not_paid"`
+	PaymentMethod string `json:"paymentMethod" query:"paymentMethod" validate:"omitempty,oneof=money transfer scheduled"`
+}
+
+type stockMovementPayload struct {
+	CandyID  int    `json:"candyId" validate:"required,min=1"`
+	Kind     string `json:"kind" validate:"required,oneof=production waste adjustment"`
+	Quantity int    `json:"quantity" validate:"required"`
+	Note     string `json:"note" validate:"max=200"`
+	Date     string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type lowStockThresholdPayload struct {
+	LowStockThreshold int `json:"lowStockThreshold" validate:"min=0"`
+}
+
+type batchPayload struct {
+	CandyID        int    `json:"candyId" validate:"required,min=1"`
+	Quantity       int    `json:"quantity" validate:"required,min=1"`
+	ProductionDate string `json:"productionDate" validate:"required,datetime=2006-01-02"`
+	ExpiryDate     string `json:"expiryDate" validate:"required,datetime=2006-01-02"`
+}
+
+type writeOffPayload struct {
+	Date string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type openDutyPayload struct {
+	OpeningCash int    `json:"openingCash" validate:"min=0"`
+	Note        string `json:"note" validate:"max=200"`
+}
+
+type closeDutyPayload struct {
+	CountedCash     int    `json:"countedCash" validate:"min=0"`
+	CountedTransfer int    `json:"countedTransfer" validate:"min=0"`
+	Note            string `json:"note" validate:"max=200"`
+}
+
+type ingredientPayload struct {
+	Name         string  `json:"name" validate:"required,min=2,max=60"`
+	Unit         string  `json:"unit" validate:"required,max=10"`
+	PackageSize  float64 `json:"packageSize" validate:"required,gt=0"`
+	PackagePrice int     `json:"packagePrice" validate:"min=0"`
+}
+
+type recipeItemPayload struct {
+	IngredientID int     `json:"ingredientId" validate:"required,min=1"`
+	Quantity     float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type recipePayload struct {
+	Yield int                 `json:"yield" validate:"required,min=1"`
+	Items []recipeItemPayload `json:"items" validate:"required,min=1,dive"`
+}
+
+type plannedBatchPayload struct {
+	CandyID  int `json:"candyId" validate:"required,min=1"`
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+type shoppingListPayload struct {
+	Batches []plannedBatchPayload `json:"batches" validate:"required,min=1,dive"`
+}
+
+type expensePayload struct {
+	Category       string `json:"category" validate:"required,oneof=packaging gas delivery other"`
+	Amount         int    `json:"amount" validate:"required,min=1"`
+	Date           string `json:"date" validate:"required,datetime=2006-01-02"`
+	Note           string `json:"note" validate:"max=200"`
+	AttachmentPath string `json:"attachmentPath" validate:"max=300"`
+}
+
+type expensesFilterPayload struct {
+	queryOptionsPayload
+
+	From     string `json:"from" query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To       string `json:"to" query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Category string `json:"category" query:"category" validate:"omitempty,oneof=packaging gas delivery other"`
+}
+
+type loginAttemptsFilterPayload struct {
+	queryOptionsPayload
+
+	From   string `json:"from" query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To     string `json:"to" query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Email  string `json:"email" query:"email" validate:"max=40"`
+	IP     string `json:"ip" query:"ip" validate:"omitempty,ip"`
+	Result string `json:"result" query:"result" validate:"omitempty,oneof=success unknown_email wrong_password locked pending"`
+}
+
+type rankingPayload struct {
+	From    string `json:"from" query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To      string `json:"to" query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Limit   int    `json:"limit" query:"limit" validate:"min=0,max=100"`
+	Compare bool   `json:"compare" query:"compare"`
+}
+
+type candiesRankingPayload struct {
+	rankingPayload
+
+	Sort string `json:"sort" query:"sort" validate:"omitempty,oneof=units revenue"`
+}
+
+type customersRankingPayload struct {
+	rankingPayload
+
+	Sort string `json:"sort" query:"sort" validate:"omitempty,oneof=spend frequency recency"`
+}
+
+type userPayload struct {
+	Name     string `json:"name" validate:"required,min=2,max=40"`
+	Email    string `json:"email" validate:"required,email,max=40"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     string `json:"role" validate:"required,oneof=owner seller viewer"`
+}
+
+type updateUserPayload struct {
+	Name     string `json:"name" validate:"required,min=2,max=40"`
+	Email    string `json:"email" validate:"required,email,max=40"`
+	Password string `json:"password" validate:"omitempty,min=8,max=72"`
+	Role     string `json:"role" validate:"required,oneof=owner seller viewer"`
+}
@@ -1,7 +1,8 @@
 package server
 
 import (
-	"context"
+	"bytes"
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"strings"
@@ -14,6 +15,22 @@ import (
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+// dateLayout is the format of the dates received in query strings
+const dateLayout = "2006-01-02"
+
+// monthLayout is the format of the months received in query strings
+const monthLayout = "2006-01"
+
+// costFields are the response fields telling what the candies cost and earn, only the roles reading the reports see them
+var costFields = map[string]bool{
+	"cost":        true,
+	"unitCost":    true,
+	"candyCost":   true,
+	"profit":      true,
+	"grossMargin": true,
+	"marginRate":  true,
+}
+
 func handleValidationError(payload interface{}, err error) map[string]string {
 	errorsMap := make(map[string]string)
 
@@ -42,6 +59,49 @@ func handleValidationError(payload interface{}, err error) map[string]string {
 	return errorsMap
 }
 
+// withoutCosts - The response as it is for the roles reading the reports, and without its cost fields for the others
+func withoutCosts(c *fiber.Ctx, response interface{}) interface{} {
+	if domain.Role(tokenClaims(c).Role).Can(domain.ReadReports) {
+		return response
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		// Nothing is better than the costs
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil
+	}
+
+	return dropCostFields(decoded)
+}
+
+func dropCostFields(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if costFields[key] {
+				delete(value, key)
+				continue
+			}
+
+			value[key] = dropCostFields(field)
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = dropCostFields(value[i])
+		}
+	}
+
+	return value
+}
+
 func (s Service) pingEndpoint(c *fiber.Ctx) {
 	c.Send("pong")
 }
@@ -49,14 +109,14 @@ func (s Service) pingEndpoint(c *fiber.Ctx) {
 func (s Service) loginEndpoint(c *fiber.Ctx) {
 	const opName infra.OpName = "server.login"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
 	payload := loginPayload{}
 	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
 
 		c.Status(422).JSON(
 			map[string]string{
@@ -68,9 +128,9 @@ func (s Service) loginEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
 
 		response := handleValidationError(payload, err)
 
@@ -79,23 +139,30 @@ func (s Service) loginEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	token, err := s.in.AuthRepo.Login(ctx, payload.Email, payload.Password)
-	if err != nil && errors.Kind(err) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+	tokens, err := s.in.AuthRepo.Login(ctx, payload.Email, payload.Password, c.IP())
+	if err != nil && errors.Kind(err) == infra.KindTooManyRequests {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"email": payload.Email,
-		})
+			"ip":    c.IP(),
+		}))
 
-		c.Status(404).JSON(map[string]interface{}{
-			"message": "User not found",
+		if retryAfter, ok := errors.Metadata(err)["retryAfter"].(int); ok {
+			c.Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+
+		c.Status(429).JSON(map[string]interface{}{
+			"message": "Too many failed logins, try again later.",
 		})
 
 		return
 	}
 
+	// Unknown e-mails fail as wrong passwords, so the response doesn't tell which e-mails have an account
 	if err != nil && errors.Kind(err) == infra.KindUnauthorized {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"email": payload.Email,
-		})
+			"ip":    c.IP(),
+		}))
 
 		c.Status(401).JSON(map[string]interface{}{
 			"message": "Wrong e-mail or password",
@@ -105,9 +172,9 @@ func (s Service) loginEndpoint(c *fiber.Ctx) {
 	}
 
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"email": payload.Email,
-		})
+		}))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -118,24 +185,18 @@ func (s Service) loginEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(
-		map[string]string{
-			"token": token,
-		},
-	)
+	c.Status(200).JSON(tokens)
 }
 
-func (s Service) registerCustomerEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.registerCustomerEndpoint"
+func (s Service) refreshTokenEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.refreshTokenEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	payload := customerPayload{}
+	payload := refreshTokenPayload{}
 	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
 		c.Status(422).JSON(
 			map[string]string{
@@ -147,9 +208,7 @@ func (s Service) registerCustomerEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
 		response := handleValidationError(payload, err)
 
@@ -158,17 +217,20 @@ func (s Service) registerCustomerEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	customerDTO := domain.Customer{
-		Name:  payload.Name,
-		Phone: payload.Phone,
-	}
+	tokens, err := s.in.AuthRepo.Refresh(ctx, payload.RefreshToken)
+	if err != nil && errors.Kind(err) == infra.KindUnauthorized {
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
-	customer, cErr := s.in.CustomersRepo.Register(ctx, customerDTO)
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": customerDTO,
+		c.Status(401).JSON(map[string]interface{}{
+			"message": "The session has ended, log in again.",
 		})
 
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -178,78 +240,59 @@ func (s Service) registerCustomerEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(customer)
+	c.Status(200).JSON(tokens)
 }
 
-func (s Service) updateCustomerEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.updateCustomerEndpoint"
+func (s Service) logoutEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.logoutEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	customerIDParam := c.Params("id")
-	customerID, err := strconv.Atoi(customerIDParam)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": customerIDParam,
-		})
-
-		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid customer id.",
-		})
-
-		return
-	}
+	// The session middleware already checked the session id
+	sessionID, _ := strconv.Atoi(tokenClaims(c).SessionID)
 
-	payload := customerPayload{}
-	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+	if err := s.in.AuthRepo.Logout(ctx, infra.ObjectID(sessionID)); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"sessionID": sessionID,
+		}))
 
-		c.Status(422).JSON(
+		c.Status(500).JSON(
 			map[string]string{
-				"message": "Invalid payload.",
+				"message": "Internal server error.",
 			},
 		)
 
 		return
 	}
 
-	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
-
-		response := handleValidationError(payload, err)
-
-		c.Status(422).JSON(response)
+	c.Status(200).JSON(map[string]string{"Message": "Logged out successfully!"})
+}
 
-		return
-	}
+func (s Service) logoutAllEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.logoutAllEndpoint"
 
-	customerDTO := domain.Customer{
-		Name:  payload.Name,
-		Phone: payload.Phone,
-	}
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
 
-	customer, cErr := s.in.CustomersRepo.Update(ctx, infra.ObjectID(customerID), customerDTO)
-	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": payload,
-		})
+	userIDClaim := tokenClaims(c).UserID
+	userID, err := strconv.Atoi(userIDClaim)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"userID": userIDClaim,
+		}))
 
-		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified customer was not found",
+		c.Status(401).JSON(map[string]interface{}{
+			"message": "The session has ended, log in again.",
 		})
 
 		return
 	}
 
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": customerDTO,
-		})
+	if err := s.in.AuthRepo.LogoutAll(ctx, infra.ObjectID(userID)); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"userID": userID,
+		}))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -260,47 +303,79 @@ func (s Service) updateCustomerEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(customer)
+	c.Status(200).JSON(map[string]string{"Message": "Logged out of all the sessions successfully!"})
 }
 
-func (s Service) deleteCustomerEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.deleteCustomerEndpoint"
+func (s Service) listLoginAttemptsEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listLoginAttemptsEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	customerIDParam := c.Params("id")
-	customerID, err := strconv.Atoi(customerIDParam)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": customerIDParam,
-		})
+	payload := loginAttemptsFilterPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
 
-		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid customer id.",
-		})
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
 
 		return
 	}
 
-	cErr := s.in.CustomersRepo.Delete(ctx, infra.ObjectID(customerID))
-	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"param": customerIDParam,
-		})
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
 
-		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified customer was not found",
-		})
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
 
 		return
 	}
 
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"param": customerIDParam,
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+		Search:    payload.Search,
+	}
+
+	filter := domain.LoginAttemptsFilter{
+		From:   payload.From,
+		To:     payload.To,
+		Email:  payload.Email,
+		IP:     payload.IP,
+		Result: domain.LoginResult(payload.Result),
+	}
+
+	attempts, err := s.in.AuthRepo.LoginAttempts(ctx, filter, opts)
+	if err != nil && errors.Kind(err) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(err).Error(),
 		})
 
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -310,18 +385,18 @@ func (s Service) deleteCustomerEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(map[string]string{"Message": "Customer deleted successfully!"})
+	c.Status(200).JSON(attempts)
 }
 
-func (s Service) listCustomersEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.listCustomersEndpoint"
+func (s Service) listUsersEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listUsersEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	customers, err := s.in.CustomersRepo.List(ctx)
+	users, err := s.in.UsersRepo.List(ctx)
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName)
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -332,20 +407,18 @@ func (s Service) listCustomersEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(customers)
+	c.Status(200).JSON(users)
 }
 
-func (s Service) registerCandyEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.registerCandyEndpoint"
+func (s Service) registerUserEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerUserEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	payload := candyPayload{}
+	payload := userPayload{}
 	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
 		c.Status(422).JSON(
 			map[string]string{
@@ -357,9 +430,9 @@ func (s Service) registerCandyEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
 
 		response := handleValidationError(payload, err)
 
@@ -368,17 +441,31 @@ func (s Service) registerCandyEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	candyDto := domain.Candy{
-		Name:  payload.Name,
-		Price: payload.Price,
+	userDTO := domain.User{
+		Name:     payload.Name,
+		Email:    payload.Email,
+		Password: payload.Password,
+		Role:     domain.Role(payload.Role),
 	}
 
-	candy, cErr := s.in.CandiesRepo.Register(ctx, candyDto)
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": candyDto,
+	user, uErr := s.in.UsersRepo.Register(ctx, userDTO)
+	if uErr != nil && errors.Kind(uErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, uErr, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": errors.Error(uErr).Error(),
 		})
 
+		return
+	}
+
+	if uErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, uErr, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -388,34 +475,82 @@ func (s Service) registerCandyEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(candy)
+	c.Status(200).JSON(user)
 }
 
-func (s Service) updateCandyEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.updateCandyEndpoint"
+func (s Service) findUserEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findUserEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	candyIDParam := c.Params("id")
-	candyID, err := strconv.Atoi(candyIDParam)
+	userIDParam := c.Params("id")
+	userID, err := strconv.Atoi(userIDParam)
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": candyIDParam,
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid user id.",
 		})
 
+		return
+	}
+
+	user, fErr := s.in.UsersRepo.Find(ctx, infra.ObjectID(userID))
+	if fErr != nil && errors.Kind(fErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified user was not found",
+		})
+
+		return
+	}
+
+	if fErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(user)
+}
+
+func (s Service) updateUserEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateUserEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	userIDParam := c.Params("id")
+	userID, err := strconv.Atoi(userIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
 		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid candy id.",
+			"message": "Invalid user id.",
 		})
 
 		return
 	}
 
-	payload := candyPayload{}
+	payload := updateUserPayload{}
 	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
 
 		c.Status(422).JSON(
 			map[string]string{
@@ -427,9 +562,9 @@ func (s Service) updateCandyEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
 
 		response := handleValidationError(payload, err)
 
@@ -438,29 +573,44 @@ func (s Service) updateCandyEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	candyDTO := domain.Candy{
-		Name:  payload.Name,
-		Price: payload.Price,
+	userDTO := domain.User{
+		Name:     payload.Name,
+		Email:    payload.Email,
+		Password: payload.Password,
+		Role:     domain.Role(payload.Role),
 	}
 
-	candy, cErr := s.in.CandiesRepo.Update(ctx, infra.ObjectID(candyID), candyDTO)
-	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": payload,
-		})
+	user, uErr := s.in.UsersRepo.Update(ctx, infra.ObjectID(userID), userDTO)
+	if uErr != nil && errors.Kind(uErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, uErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
 
 		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified candy was not found",
+			"message": "The specified user was not found",
 		})
 
 		return
 	}
 
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": candyDTO,
+	if uErr != nil && errors.Kind(uErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, uErr, opName, infra.Metadata{
+			"email": payload.Email,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": errors.Error(uErr).Error(),
 		})
 
+		return
+	}
+
+	if uErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, uErr, opName, infra.Metadata{
+			"param": userIDParam,
+			"email": payload.Email,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -470,47 +620,59 @@ func (s Service) updateCandyEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(candy)
+	c.Status(200).JSON(user)
 }
 
-func (s Service) deleteCandyEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.deleteCandyEndpoint"
+func (s Service) deactivateUserEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deactivateUserEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	candyIDParam := c.Params("id")
-	candyID, err := strconv.Atoi(candyIDParam)
+	userIDParam := c.Params("id")
+	userID, err := strconv.Atoi(userIDParam)
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": candyIDParam,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
 
 		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid candy id.",
+			"message": "Invalid user id.",
 		})
 
 		return
 	}
 
-	cErr := s.in.CandiesRepo.Delete(ctx, infra.ObjectID(candyID))
-	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"param": candyIDParam,
-		})
+	dErr := s.in.UsersRepo.Deactivate(ctx, infra.ObjectID(userID))
+	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
 
 		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified candy was not found",
+			"message": "The specified user was not found",
 		})
 
 		return
 	}
 
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"param": candyIDParam,
+	if dErr != nil && errors.Kind(dErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": errors.Error(dErr).Error(),
 		})
 
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": userIDParam,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -520,19 +682,3302 @@ func (s Service) deleteCandyEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(map[string]string{"Message": "Candy deleted successfully!"})
+	c.Status(200).JSON(map[string]string{"Message": "User deactivated successfully!"})
 }
 
-func (s Service) listCandiesEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.listCandiesEndpoint"
+func (s Service) registerCustomerEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerCustomerEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	candies, err := s.in.CandiesRepo.List(ctx)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName)
-
+	payload := customerPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	customerDTO := domain.Customer{
+		Name:  payload.Name,
+		Phone: payload.Phone,
+	}
+
+	customer, cErr := s.in.CustomersRepo.Register(ctx, customerDTO)
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": customerDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(customer)
+}
+
+func (s Service) findCustomerEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findCustomerEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	customerIDParam := c.Params("id")
+	customerID, err := strconv.Atoi(customerIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid customer id.",
+		})
+
+		return
+	}
+
+	customer, fErr := s.in.CustomersRepo.Find(ctx, infra.ObjectID(customerID))
+	if fErr != nil && errors.Kind(fErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if fErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	balance, bErr := s.in.PaymentsRepo.Balance(ctx, customer.ID)
+	if bErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	lastPurchase, lErr := s.in.SalesRepo.List(ctx, domain.SalesFilter{CustomerID: customer.ID}, domain.QueryOptions{
+		Limit:     1,
+		Sort:      "date",
+		Direction: domain.Descending,
+	})
+
+	if lErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	details := domain.CustomerDetails{
+		Customer: *customer,
+		Balance:  *balance,
+	}
+
+	if len(lastPurchase.Data) > 0 {
+		details.LastPurchase = &lastPurchase.Data[0]
+	}
+
+	c.Status(200).JSON(withoutCosts(c, details))
+}
+
+func (s Service) updateCustomerEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateCustomerEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	customerIDParam := c.Params("id")
+	customerID, err := strconv.Atoi(customerIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid customer id.",
+		})
+
+		return
+	}
+
+	payload := customerPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	customerDTO := domain.Customer{
+		Name:  payload.Name,
+		Phone: payload.Phone,
+	}
+
+	customer, cErr := s.in.CustomersRepo.Update(ctx, infra.ObjectID(customerID), customerDTO)
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": customerDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(customer)
+}
+
+func (s Service) deleteCustomerEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteCustomerEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	customerIDParam := c.Params("id")
+	customerID, err := strconv.Atoi(customerIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid customer id.",
+		})
+
+		return
+	}
+
+	cErr := s.in.CustomersRepo.Delete(ctx, infra.ObjectID(customerID))
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Customer deleted successfully!"})
+}
+
+func (s Service) listCustomersEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listCustomersEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := queryOptionsPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+		Search:    payload.Search,
+	}
+
+	customers, err := s.in.CustomersRepo.List(ctx, opts)
+	if err != nil && errors.Kind(err) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(err).Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(customers)
+}
+
+func (s Service) registerCandyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerCandyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := candyPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	candyDto := domain.Candy{
+		Name:  payload.Name,
+		Price: payload.Price,
+		Cost:  payload.Cost,
+	}
+
+	candy, cErr := s.in.CandiesRepo.Register(ctx, candyDto)
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": candyDto,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(candy)
+}
+
+func (s Service) findCandyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findCandyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	candy, fErr := s.in.CandiesRepo.Find(ctx, infra.ObjectID(candyID))
+	if fErr != nil && errors.Kind(fErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if fErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, candy))
+}
+
+func (s Service) candyCostsEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.candyCostsEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	costs, cErr := s.in.CandiesRepo.Costs(ctx, infra.ObjectID(candyID))
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(costs)
+}
+
+func (s Service) updateCandyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateCandyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	payload := candyPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	candyDTO := domain.Candy{
+		Name:  payload.Name,
+		Price: payload.Price,
+		Cost:  payload.Cost,
+	}
+
+	candy, cErr := s.in.CandiesRepo.Update(ctx, infra.ObjectID(candyID), candyDTO)
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": candyDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(candy)
+}
+
+func (s Service) deleteCandyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteCandyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	cErr := s.in.CandiesRepo.Delete(ctx, infra.ObjectID(candyID))
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Candy deleted successfully!"})
+}
+
+func (s Service) listCandiesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listCandiesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := queryOptionsPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+		Search:    payload.Search,
+	}
+
+	candies, err := s.in.CandiesRepo.List(ctx, opts)
+	if err != nil && errors.Kind(err) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(err).Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, candies))
+}
+
+func (s Service) listMonthsThatHasSalesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listMonthsThatHasSalesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	months, err := s.in.SalesRepo.Months(ctx)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(months)
+}
+
+func (s Service) listSalesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listSalesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := salesFilterPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+		Search:    payload.Search,
+	}
+
+	filter := domain.SalesFilter{
+		From:          payload.From,
+		To:            payload.To,
+		CustomerID:    infra.ObjectID(payload.CustomerID),
+		CandyID:       infra.ObjectID(payload.CandyID),
+		Status:        domain.Status(payload.Status),
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+	}
+
+	sales, err := s.in.SalesRepo.List(ctx, filter, opts)
+	if err != nil && errors.Kind(err) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(err).Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, sales))
+}
+
+func (s Service) listMonthSalesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listMonthSales"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	monthParam := c.Params("month")
+	month, err := strconv.Atoi(monthParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": monthParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid month.",
+		})
+
+		return
+	}
+
+	yearParam := c.Params("year")
+	year, err := strconv.Atoi(yearParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": yearParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid year.",
+		})
+
+		return
+	}
+
+	monthSales, sErr := s.in.SalesRepo.MonthSales(ctx, month, year)
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"monthParam": monthParam,
+			"yearParam":  yearParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(monthSales)
+}
+
+func (s Service) registerSaleEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerSaleEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := salePayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	saleDTO := domain.Sale{
+		CustomerID:    infra.ObjectID(payload.CustomerID),
+		CandyID:       infra.ObjectID(payload.CandyID),
+		Status:        domain.Status(payload.Status),
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+		Date:          payload.Date,
+	}
+
+	sale, sErr := s.in.SalesRepo.Register(ctx, saleDTO)
+	if sErr != nil && errors.Kind(sErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"payload": saleDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if sErr != nil && errors.Kind(sErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"payload": saleDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "Not enough stock.",
+		})
+
+		return
+	}
+
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"payload": saleDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(sale)
+}
+
+func (s Service) findSaleEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findSaleEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	saleIDParam := c.Params("id")
+	saleID, err := strconv.Atoi(saleIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid sale id.",
+		})
+
+		return
+	}
+
+	sale, fErr := s.in.SalesRepo.Find(ctx, infra.ObjectID(saleID))
+	if fErr != nil && errors.Kind(fErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified sale was not found",
+		})
+
+		return
+	}
+
+	if fErr != nil && errors.Kind(fErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "This sale is an order with many items, use /order/" + saleIDParam + " instead.",
+		})
+
+		return
+	}
+
+	if fErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	customer, cuErr := s.in.CustomersRepo.Find(ctx, sale.CustomerID)
+	if cuErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cuErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	candy, caErr := s.in.CandiesRepo.Find(ctx, sale.CandyID)
+	if caErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, caErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, domain.SaleDetails{
+		Sale:     *sale,
+		Customer: *customer,
+		Candy:    *candy,
+	}))
+}
+
+func (s Service) updateSaleEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateSaleEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	saleIDParam := c.Params("id")
+	saleID, err := strconv.Atoi(saleIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid sale id.",
+		})
+
+		return
+	}
+
+	payload := updateSalePayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	saleDTO := domain.Sale{
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+		Status:        domain.Status(payload.Status),
+	}
+
+	sale, cErr := s.in.SalesRepo.Update(ctx, infra.ObjectID(saleID), saleDTO)
+	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified sale was not found",
+		})
+
+		return
+	}
+
+	if cErr != nil && errors.Kind(cErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "This sale is an order with many items, use /order/" + saleIDParam + " instead.",
+		})
+
+		return
+	}
+
+	if cErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, cErr, opName, infra.Metadata{
+			"payload": saleDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(sale)
+}
+
+func (s Service) deleteSaleEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteSaleEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	saleIDParam := c.Params("id")
+	saleID, err := strconv.Atoi(saleIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid sale id.",
+		})
+
+		return
+	}
+
+	dErr := s.in.SalesRepo.Delete(ctx, infra.ObjectID(saleID))
+	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified sale was not found",
+		})
+
+		return
+	}
+
+	if dErr != nil && errors.Kind(dErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "This sale is an order with many items, use /order/" + saleIDParam + " instead.",
+		})
+
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": saleIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Sale deleted successfully!"})
+}
+
+func (s Service) registerOrderEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerOrderEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := orderPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	orderDTO := domain.Order{
+		CustomerID:    infra.ObjectID(payload.CustomerID),
+		Status:        domain.Status(payload.Status),
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+		Date:          payload.Date,
+		Items:         []domain.OrderItem{},
+	}
+
+	for _, item := range payload.Items {
+		orderDTO.Items = append(orderDTO.Items, domain.OrderItem{
+			CandyID:  infra.ObjectID(item.CandyID),
+			Quantity: item.Quantity,
+			Discount: item.Discount,
+		})
+	}
+
+	order, oErr := s.in.OrdersRepo.Register(ctx, orderDTO)
+	if oErr != nil && errors.Kind(oErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": orderDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if oErr != nil && errors.Kind(oErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": orderDTO,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid order.",
+		})
+
+		return
+	}
+
+	if oErr != nil && errors.Kind(oErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": orderDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "Not enough stock.",
+		})
+
+		return
+	}
+
+	if oErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": orderDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, order))
+}
+
+func (s Service) findOrderEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findOrderEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	orderIDParam := c.Params("id")
+	orderID, err := strconv.Atoi(orderIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid order id.",
+		})
+
+		return
+	}
+
+	order, oErr := s.in.OrdersRepo.Find(ctx, infra.ObjectID(orderID))
+	if oErr != nil && errors.Kind(oErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified order was not found",
+		})
+
+		return
+	}
+
+	if oErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, order))
+}
+
+func (s Service) updateOrderEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateOrderEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	orderIDParam := c.Params("id")
+	orderID, err := strconv.Atoi(orderIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid order id.",
+		})
+
+		return
+	}
+
+	payload := updateOrderPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	orderDTO := domain.Order{
+		Status:        domain.Status(payload.Status),
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+	}
+
+	order, oErr := s.in.OrdersRepo.Update(ctx, infra.ObjectID(orderID), orderDTO)
+	if oErr != nil && errors.Kind(oErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified order was not found",
+		})
+
+		return
+	}
+
+	if oErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, oErr, opName, infra.Metadata{
+			"payload": orderDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, order))
+}
+
+func (s Service) deleteOrderEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteOrderEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	orderIDParam := c.Params("id")
+	orderID, err := strconv.Atoi(orderIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid order id.",
+		})
+
+		return
+	}
+
+	dErr := s.in.OrdersRepo.Delete(ctx, infra.ObjectID(orderID))
+	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified order was not found",
+		})
+
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": orderIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Order deleted successfully!"})
+}
+
+func (s Service) registerPaymentEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerPaymentEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := paymentPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	paymentDTO := domain.Payment{
+		CustomerID:    infra.ObjectID(payload.CustomerID),
+		Amount:        payload.Amount,
+		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
+		Date:          payload.Date,
+		Note:          payload.Note,
+	}
+
+	payment, pErr := s.in.PaymentsRepo.Register(ctx, paymentDTO)
+	if pErr != nil && errors.Kind(pErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, pErr, opName, infra.Metadata{
+			"payload": paymentDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if pErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, pErr, opName, infra.Metadata{
+			"payload": paymentDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(payment)
+}
+
+func (s Service) findPaymentEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findPaymentEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	paymentIDParam := c.Params("id")
+	paymentID, err := strconv.Atoi(paymentIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid payment id.",
+		})
+
+		return
+	}
+
+	payment, pErr := s.in.PaymentsRepo.Find(ctx, infra.ObjectID(paymentID))
+	if pErr != nil && errors.Kind(pErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, pErr, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified payment was not found",
+		})
+
+		return
+	}
+
+	if pErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, pErr, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(payment)
+}
+
+func (s Service) deletePaymentEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deletePaymentEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	paymentIDParam := c.Params("id")
+	paymentID, err := strconv.Atoi(paymentIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid payment id.",
+		})
+
+		return
+	}
+
+	dErr := s.in.PaymentsRepo.Delete(ctx, infra.ObjectID(paymentID))
+	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified payment was not found",
+		})
+
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": paymentIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Payment deleted successfully!"})
+}
+
+func (s Service) customerBalanceEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.customerBalanceEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	customerIDParam := c.Params("id")
+	customerID, err := strconv.Atoi(customerIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid customer id.",
+		})
+
+		return
+	}
+
+	balance, bErr := s.in.PaymentsRepo.Balance(ctx, infra.ObjectID(customerID))
+	if bErr != nil && errors.Kind(bErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if bErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(balance)
+}
+
+func (s Service) debtorsReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.debtorsReportEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	sortParam := c.Query("sort", string(domain.DebtorsByAmount))
+	if sortParam != string(domain.DebtorsByAmount) && sortParam != string(domain.DebtorsByAge) {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid debtors sort.", opName, infra.Metadata{
+			"sort": sortParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid sort, expected amount or age.",
+		})
+
+		return
+	}
+
+	report, rErr := s.in.SalesRepo.Debtors(ctx, domain.DebtorsSort(sortParam))
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"sort": sortParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(report)
+}
+
+func (s Service) profitReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.profitReportEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	toParam := c.Query("to", time.Now().Format(dateLayout))
+	to, err := time.Parse(dateLayout, toParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"to": toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid to date, expected YYYY-MM-DD.",
+		})
+
+		return
+	}
+
+	fromParam := c.Query("from", to.AddDate(0, 0, 1-to.Day()).Format(dateLayout))
+	from, err := time.Parse(dateLayout, fromParam)
+	if err != nil || from.After(to) {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid report period.", opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from date, expected YYYY-MM-DD before the to date.",
+		})
+
+		return
+	}
+
+	report, rErr := s.in.SalesRepo.Profit(ctx, fromParam, toParam)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(report)
+}
+
+func (s Service) customerStatementEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.customerStatementEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	customerIDParam := c.Params("id")
+	customerID, err := strconv.Atoi(customerIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid customer id.",
+		})
+
+		return
+	}
+
+	toParam := c.Query("to", time.Now().Format(dateLayout))
+	to, err := time.Parse(dateLayout, toParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"to": toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid to date, expected YYYY-MM-DD.",
+		})
+
+		return
+	}
+
+	fromParam := c.Query("from", to.AddDate(0, 0, 1-to.Day()).Format(dateLayout))
+	from, err := time.Parse(dateLayout, fromParam)
+	if err != nil || from.After(to) {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid statement period.", opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from date, expected YYYY-MM-DD before the to date.",
+		})
+
+		return
+	}
+
+	statement, sErr := s.in.CustomersRepo.Statement(ctx, infra.ObjectID(customerID), fromParam, toParam)
+	if sErr != nil && errors.Kind(sErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"param": customerIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified customer was not found",
+		})
+
+		return
+	}
+
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"param": customerIDParam,
+			"from":  fromParam,
+			"to":    toParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(statement)
+}
+
+func (s Service) inventoryEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.inventoryEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	inventory, err := s.in.InventoryRepo.Levels(ctx)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(inventory)
+}
+
+func (s Service) registerStockMovementEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerStockMovementEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := stockMovementPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	movementDTO := domain.StockMovement{
+		CandyID:  infra.ObjectID(payload.CandyID),
+		Kind:     domain.StockMovementKind(payload.Kind),
+		Quantity: payload.Quantity,
+		Note:     payload.Note,
+		Date:     payload.Date,
+	}
+
+	level, mErr := s.in.InventoryRepo.Move(ctx, movementDTO)
+	if mErr != nil && errors.Kind(mErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"payload": movementDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if mErr != nil && errors.Kind(mErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"payload": movementDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "Not enough stock.",
+		})
+
+		return
+	}
+
+	if mErr != nil && errors.Kind(mErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"payload": movementDTO,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid stock movement.",
+		})
+
+		return
+	}
+
+	if mErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"payload": movementDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(level)
+}
+
+func (s Service) listStockMovementsEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listStockMovementsEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("candyId")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	payload := queryOptionsPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+	}
+
+	movements, mErr := s.in.InventoryRepo.Movements(ctx, infra.ObjectID(candyID), opts)
+	if mErr != nil && errors.Kind(mErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(mErr).Error(),
+		})
+
+		return
+	}
+
+	if mErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, mErr, opName, infra.Metadata{
+			"options": opts,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(movements)
+}
+
+func (s Service) updateLowStockThresholdEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateLowStockThresholdEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("candyId")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	payload := lowStockThresholdPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	level, lErr := s.in.InventoryRepo.SetLowStockThreshold(ctx, infra.ObjectID(candyID), payload.LowStockThreshold)
+	if lErr != nil && errors.Kind(lErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if lErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"param":   candyIDParam,
+			"payload": payload,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(level)
+}
+
+func (s Service) registerBatchEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerBatchEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := batchPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	if payload.ExpiryDate < payload.ProductionDate {
+		s.in.Reporter.Report(errors.New(ctx, "The batch expires before being produced.", opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "The expiry date must not be before the production date.",
+		})
+
+		return
+	}
+
+	batchDTO := domain.Batch{
+		CandyID:        infra.ObjectID(payload.CandyID),
+		Produced:       payload.Quantity,
+		ProductionDate: payload.ProductionDate,
+		ExpiryDate:     payload.ExpiryDate,
+	}
+
+	batch, bErr := s.in.BatchesRepo.Register(ctx, batchDTO)
+	if bErr != nil && errors.Kind(bErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"payload": batchDTO,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid batch.",
+		})
+
+		return
+	}
+
+	if bErr != nil && errors.Kind(bErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"payload": batchDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy was not found",
+		})
+
+		return
+	}
+
+	if bErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"payload": batchDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(batch)
+}
+
+func (s Service) writeOffExpiredBatchesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.writeOffExpiredBatchesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := writeOffPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	date := payload.Date
+	if date == "" {
+		date = time.Now().Format(dateLayout)
+	}
+
+	batches, bErr := s.in.BatchesRepo.WriteOffExpired(ctx, date)
+	if bErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, bErr, opName, infra.Metadata{
+			"date": date,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(batches)
+}
+
+func (s Service) expiringBatchesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.expiringBatchesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	daysParam := c.Query("days", "7")
+	days, err := strconv.Atoi(daysParam)
+	if err != nil || days < 0 {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid days.", opName, infra.Metadata{
+			"days": daysParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid days, expected a positive number.",
+		})
+
+		return
+	}
+
+	report, rErr := s.in.BatchesRepo.Expiring(ctx, days)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"days": days,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(report)
+}
+
+func (s Service) writtenOffBatchesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.writtenOffBatchesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	toParam := c.Query("to", time.Now().Format(dateLayout))
+	to, err := time.Parse(dateLayout, toParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"to": toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid to date, expected YYYY-MM-DD.",
+		})
+
+		return
+	}
+
+	fromParam := c.Query("from", to.AddDate(0, 0, 1-to.Day()).Format(dateLayout))
+	from, err := time.Parse(dateLayout, fromParam)
+	if err != nil || from.After(to) {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid report period.", opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from date, expected YYYY-MM-DD before the to date.",
+		})
+
+		return
+	}
+
+	report, rErr := s.in.BatchesRepo.WrittenOff(ctx, fromParam, toParam)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(report)
+}
+
+func (s Service) openDutyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.openDutyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := openDutyPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	dutyDTO := domain.Duty{
+		OpeningCash: payload.OpeningCash,
+		Note:        payload.Note,
+	}
+
+	duty, dErr := s.in.DutiesRepo.Open(ctx, dutyDTO)
+	if dErr != nil && errors.Kind(dErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"payload": dutyDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "There is already an open duty.",
+		})
+
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"payload": dutyDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(duty)
+}
+
+func (s Service) closeDutyEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.closeDutyEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	dutyIDParam := c.Params("id")
+	dutyID, err := strconv.Atoi(dutyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid duty id.",
+		})
+
+		return
+	}
+
+	payload := closeDutyPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	dutyDTO := domain.Duty{
+		CountedCash:     payload.CountedCash,
+		CountedTransfer: payload.CountedTransfer,
+		Note:            payload.Note,
+	}
+
+	_, dErr := s.in.DutiesRepo.Close(ctx, infra.ObjectID(dutyID), dutyDTO)
+	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified duty was not found",
+		})
+
+		return
+	}
+
+	if dErr != nil && errors.Kind(dErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "The duty is already closed.",
+		})
+
+		return
+	}
+
+	if dErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, dErr, opName, infra.Metadata{
+			"param":   dutyIDParam,
+			"payload": dutyDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	summary, sErr := s.in.DutiesRepo.Summary(ctx, infra.ObjectID(dutyID))
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(summary)
+}
+
+func (s Service) dutySummaryEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.dutySummaryEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	dutyIDParam := c.Params("id")
+	dutyID, err := strconv.Atoi(dutyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid duty id.",
+		})
+
+		return
+	}
+
+	summary, sErr := s.in.DutiesRepo.Summary(ctx, infra.ObjectID(dutyID))
+	if sErr != nil && errors.Kind(sErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified duty was not found",
+		})
+
+		return
+	}
+
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"param": dutyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(summary)
+}
+
+func (s Service) listIngredientsEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listIngredientsEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	ingredients, err := s.in.IngredientsRepo.List(ctx)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(ingredients)
+}
+
+func (s Service) registerIngredientEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerIngredientEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := ingredientPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	ingredientDTO := domain.Ingredient{
+		Name:         payload.Name,
+		Unit:         payload.Unit,
+		PackageSize:  payload.PackageSize,
+		PackagePrice: payload.PackagePrice,
+	}
+
+	ingredient, iErr := s.in.IngredientsRepo.Register(ctx, ingredientDTO)
+	if iErr != nil && errors.Kind(iErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"payload": ingredientDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "There is already an ingredient with this name.",
+		})
+
+		return
+	}
+
+	if iErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"payload": ingredientDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(ingredient)
+}
+
+func (s Service) findIngredientEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findIngredientEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	ingredientIDParam := c.Params("id")
+	ingredientID, err := strconv.Atoi(ingredientIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid ingredient id.",
+		})
+
+		return
+	}
+
+	ingredient, fErr := s.in.IngredientsRepo.Find(ctx, infra.ObjectID(ingredientID))
+	if fErr != nil && errors.Kind(fErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified ingredient was not found",
+		})
+
+		return
+	}
+
+	if fErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, fErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(ingredient)
+}
+
+func (s Service) updateIngredientEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateIngredientEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	ingredientIDParam := c.Params("id")
+	ingredientID, err := strconv.Atoi(ingredientIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid ingredient id.",
+		})
+
+		return
+	}
+
+	payload := ingredientPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	ingredientDTO := domain.Ingredient{
+		Name:         payload.Name,
+		Unit:         payload.Unit,
+		PackageSize:  payload.PackageSize,
+		PackagePrice: payload.PackagePrice,
+	}
+
+	ingredient, iErr := s.in.IngredientsRepo.Update(ctx, infra.ObjectID(ingredientID), ingredientDTO)
+	if iErr != nil && errors.Kind(iErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified ingredient was not found",
+		})
+
+		return
+	}
+
+	if iErr != nil && errors.Kind(iErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"payload": ingredientDTO,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "There is already an ingredient with this name.",
+		})
+
+		return
+	}
+
+	if iErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"param":   ingredientIDParam,
+			"payload": ingredientDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(ingredient)
+}
+
+func (s Service) deleteIngredientEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteIngredientEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	ingredientIDParam := c.Params("id")
+	ingredientID, err := strconv.Atoi(ingredientIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid ingredient id.",
+		})
+
+		return
+	}
+
+	iErr := s.in.IngredientsRepo.Delete(ctx, infra.ObjectID(ingredientID))
+	if iErr != nil && errors.Kind(iErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified ingredient was not found",
+		})
+
+		return
+	}
+
+	if iErr != nil && errors.Kind(iErr) == infra.KindConflict {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(409).JSON(map[string]interface{}{
+			"message": "The ingredient is used by recipes.",
+		})
+
+		return
+	}
+
+	if iErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, iErr, opName, infra.Metadata{
+			"param": ingredientIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Ingredient deleted successfully!"})
+}
+
+func (s Service) findRecipeEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findRecipeEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	recipe, rErr := s.in.RecipesRepo.Find(ctx, infra.ObjectID(candyID))
+	if rErr != nil && errors.Kind(rErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy has no recipe",
+		})
+
+		return
+	}
+
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(withoutCosts(c, recipe))
+}
+
+func (s Service) saveRecipeEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.saveRecipeEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	payload := recipePayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	recipeDTO := domain.Recipe{
+		CandyID: infra.ObjectID(candyID),
+		Yield:   payload.Yield,
+		Items:   []domain.RecipeItem{},
+	}
+
+	for _, item := range payload.Items {
+		recipeDTO.Items = append(recipeDTO.Items, domain.RecipeItem{
+			IngredientID: infra.ObjectID(item.IngredientID),
+			Quantity:     item.Quantity,
+		})
+	}
+
+	recipe, rErr := s.in.RecipesRepo.Save(ctx, recipeDTO)
+	if rErr != nil && errors.Kind(rErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"payload": recipeDTO,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy or ingredient was not found",
+		})
+
+		return
+	}
+
+	if rErr != nil && errors.Kind(rErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"payload": recipeDTO,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(rErr).Error(),
+		})
+
+		return
+	}
+
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"payload": recipeDTO,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(recipe)
+}
+
+func (s Service) deleteRecipeEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteRecipeEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	candyIDParam := c.Params("id")
+	candyID, err := strconv.Atoi(candyIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid candy id.",
+		})
+
+		return
+	}
+
+	rErr := s.in.RecipesRepo.Delete(ctx, infra.ObjectID(candyID))
+	if rErr != nil && errors.Kind(rErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified candy has no recipe",
+		})
+
+		return
+	}
+
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"param": candyIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Recipe deleted successfully!"})
+}
+
+func (s Service) shoppingListEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.shoppingListEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := shoppingListPayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	batches := []domain.PlannedBatch{}
+
+	for _, batch := range payload.Batches {
+		batches = append(batches, domain.PlannedBatch{
+			CandyID:  infra.ObjectID(batch.CandyID),
+			Quantity: batch.Quantity,
+		})
+	}
+
+	list, lErr := s.in.RecipesRepo.ShoppingList(ctx, batches)
+	if lErr != nil && errors.Kind(lErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"payload": batches,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "A specified candy has no recipe",
+		})
+
+		return
+	}
+
+	if lErr != nil && errors.Kind(lErr) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"payload": batches,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(lErr).Error(),
+		})
+
+		return
+	}
+
+	if lErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, lErr, opName, infra.Metadata{
+			"payload": batches,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(list)
+}
+
+func (s Service) listExpensesEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.listExpensesEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := expensesFilterPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	opts := domain.QueryOptions{
+		Limit:     payload.Limit,
+		Offset:    payload.Offset,
+		Cursor:    payload.Cursor,
+		Sort:      payload.Sort,
+		Direction: domain.SortDirection(payload.Direction),
+		Search:    payload.Search,
+	}
+
+	filter := domain.ExpensesFilter{
+		From:     payload.From,
+		To:       payload.To,
+		Category: domain.ExpenseCategory(payload.Category),
+	}
+
+	expenses, err := s.in.ExpensesRepo.List(ctx, filter, opts)
+	if err != nil && errors.Kind(err) == infra.KindBadRequest {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": errors.Error(err).Error(),
+		})
+
+		return
+	}
+
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"filter":  filter,
+			"options": opts,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -542,18 +3987,55 @@ func (s Service) listCandiesEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(candies)
+	c.Status(200).JSON(expenses)
 }
 
-func (s Service) listMonthsThatHasSalesEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.listMonthsThatHasSalesEndpoint"
+func (s Service) registerExpenseEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.registerExpenseEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	months, err := s.in.SalesRepo.Months(ctx)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName)
+	payload := expensePayload{}
+	if err := c.BodyParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid payload.",
+			},
+		)
+
+		return
+	}
+
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
+
+		return
+	}
+
+	expenseDTO := domain.Expense{
+		Category:       domain.ExpenseCategory(payload.Category),
+		Amount:         payload.Amount,
+		Date:           payload.Date,
+		Note:           payload.Note,
+		AttachmentPath: payload.AttachmentPath,
+	}
+
+	expense, eErr := s.in.ExpensesRepo.Register(ctx, expenseDTO)
+	if eErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"payload": expenseDTO,
+		}))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -564,49 +4046,46 @@ func (s Service) listMonthsThatHasSalesEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(months)
+	c.Status(200).JSON(expense)
 }
 
-func (s Service) listMonthSalesEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.listMonthSales"
+func (s Service) findExpenseEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.findExpenseEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	monthParam := c.Params("month")
-	month, err := strconv.Atoi(monthParam)
+	expenseIDParam := c.Params("id")
+	expenseID, err := strconv.Atoi(expenseIDParam)
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": monthParam,
-		})
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
 
 		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid month.",
+			"message": "Invalid expense id.",
 		})
 
 		return
 	}
 
-	yearParam := c.Params("year")
-	year, err := strconv.Atoi(yearParam)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": yearParam,
-		})
+	expense, eErr := s.in.ExpensesRepo.Find(ctx, infra.ObjectID(expenseID))
+	if eErr != nil && errors.Kind(eErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
 
-		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid year.",
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified expense was not found",
 		})
 
 		return
 	}
 
-	monthSales, sErr := s.in.SalesRepo.MonthSales(ctx, month, year)
-	if sErr != nil {
-		s.errCh <- errors.New(ctx, sErr, opName, infra.Metadata{
-			"monthParam": monthParam,
-			"yearParam":  yearParam,
-		})
+	if eErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -617,20 +4096,34 @@ func (s Service) listMonthSalesEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(monthSales)
+	c.Status(200).JSON(expense)
 }
 
-func (s Service) registerSaleEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.registerSaleEndpoint"
+func (s Service) updateExpenseEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.updateExpenseEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	payload := salePayload{}
+	expenseIDParam := c.Params("id")
+	expenseID, err := strconv.Atoi(expenseIDParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid expense id.",
+		})
+
+		return
+	}
+
+	payload := expensePayload{}
 	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"payload": payload,
-		})
+		}))
 
 		c.Status(422).JSON(
 			map[string]string{
@@ -642,9 +4135,9 @@ func (s Service) registerSaleEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"payload": payload,
-		})
+		}))
 
 		response := handleValidationError(payload, err)
 
@@ -653,20 +4146,33 @@ func (s Service) registerSaleEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	saleDTO := domain.Sale{
-		CustomerID:    infra.ObjectID(payload.CustomerID),
-		CandyID:       infra.ObjectID(payload.CandyID),
-		Status:        domain.Status(payload.Status),
-		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
-		Date:          payload.Date,
+	expenseDTO := domain.Expense{
+		Category:       domain.ExpenseCategory(payload.Category),
+		Amount:         payload.Amount,
+		Date:           payload.Date,
+		Note:           payload.Note,
+		AttachmentPath: payload.AttachmentPath,
 	}
 
-	sale, sErr := s.in.SalesRepo.Register(ctx, saleDTO)
-	if sErr != nil {
-		s.errCh <- errors.New(ctx, sErr, opName, infra.Metadata{
-			"payload": saleDTO,
+	expense, eErr := s.in.ExpensesRepo.Update(ctx, infra.ObjectID(expenseID), expenseDTO)
+	if eErr != nil && errors.Kind(eErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified expense was not found",
 		})
 
+		return
+	}
+
+	if eErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param":   expenseIDParam,
+			"payload": expenseDTO,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -676,38 +4182,181 @@ func (s Service) registerSaleEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(sale)
+	c.Status(200).JSON(expense)
 }
 
-func (s Service) updateSaleEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.updateSaleEndpoint"
+func (s Service) deleteExpenseEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.deleteExpenseEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	saleIDParam := c.Params("id")
-	saleID, err := strconv.Atoi(saleIDParam)
+	expenseIDParam := c.Params("id")
+	expenseID, err := strconv.Atoi(expenseIDParam)
 	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": saleIDParam,
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid expense id.",
+		})
+
+		return
+	}
+
+	eErr := s.in.ExpensesRepo.Delete(ctx, infra.ObjectID(expenseID))
+	if eErr != nil && errors.Kind(eErr) == infra.KindNotFound {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
+
+		c.Status(404).JSON(map[string]interface{}{
+			"message": "The specified expense was not found",
 		})
 
+		return
+	}
+
+	if eErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, eErr, opName, infra.Metadata{
+			"param": expenseIDParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(map[string]string{"Message": "Expense deleted successfully!"})
+}
+
+func (s Service) profitAndLossReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.profitAndLossReportEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	monthParam := c.Params("month")
+	month, err := strconv.Atoi(monthParam)
+	if err != nil || month < 1 || month > 12 {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid month.", opName, infra.Metadata{
+			"param": monthParam,
+		}))
+
 		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid sale id.",
+			"message": "Invalid month.",
 		})
 
 		return
 	}
 
-	payload := updateSalePayload{}
-	if err := c.BodyParser(&payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"payload": payload,
+	yearParam := c.Params("year")
+	year, err := strconv.Atoi(yearParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"param": yearParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid year.",
+		})
+
+		return
+	}
+
+	report, rErr := s.in.ExpensesRepo.ProfitAndLoss(ctx, month, year)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"monthParam": monthParam,
+			"yearParam":  yearParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(report)
+}
+
+func (s Service) salesSummaryReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.salesSummaryReportEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	toParam := c.Query("to", time.Now().Format(monthLayout))
+	to, err := time.Parse(monthLayout, toParam)
+	if err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"to": toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid to month, expected YYYY-MM.",
+		})
+
+		return
+	}
+
+	fromParam := c.Query("from", to.AddDate(0, 1-int(to.Month()), 0).Format(monthLayout))
+	from, err := time.Parse(monthLayout, fromParam)
+	if err != nil || from.After(to) {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid report period.", opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from month, expected YYYY-MM before the to month.",
 		})
 
+		return
+	}
+
+	summary, sErr := s.in.SalesRepo.Summary(ctx, fromParam, toParam)
+	if sErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, sErr, opName, infra.Metadata{
+			"from": fromParam,
+			"to":   toParam,
+		}))
+
+		c.Status(500).JSON(
+			map[string]string{
+				"message": "Internal server error.",
+			},
+		)
+
+		return
+	}
+
+	c.Status(200).JSON(summary)
+}
+
+func (s Service) candiesRankingReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.candiesRankingReportEndpoint"
+
+	ctx, cancel := s.requestContext(c)
+	defer cancel()
+
+	payload := candiesRankingPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
+
 		c.Status(422).JSON(
 			map[string]string{
-				"message": "Invalid payload.",
+				"message": "Invalid query string.",
 			},
 		)
 
@@ -715,9 +4364,9 @@ func (s Service) updateSaleEndpoint(c *fiber.Ctx) {
 	}
 
 	if err := s.in.Validator.Struct(payload); err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
 			"payload": payload,
-		})
+		}))
 
 		response := handleValidationError(payload, err)
 
@@ -726,28 +4375,30 @@ func (s Service) updateSaleEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	saleDTO := domain.Sale{
-		PaymentMethod: domain.PaymentMethod(payload.PaymentMethod),
-		Status:        domain.Status(payload.Status),
-	}
-
-	sale, cErr := s.in.SalesRepo.Update(ctx, infra.ObjectID(saleID), saleDTO)
-	if cErr != nil && errors.Kind(cErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
+	opts, ok := rankingOptions(payload.rankingPayload)
+	if !ok {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid report period.", opName, infra.Metadata{
 			"payload": payload,
-		})
+		}))
 
-		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified sale was not found",
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from date, expected YYYY-MM-DD before the to date.",
 		})
 
 		return
 	}
 
-	if cErr != nil {
-		s.errCh <- errors.New(ctx, cErr, opName, infra.Metadata{
-			"payload": saleDTO,
-		})
+	sortBy := domain.CandiesRankingSort(payload.Sort)
+	if sortBy == "" {
+		sortBy = domain.CandiesByUnits
+	}
+
+	ranking, rErr := s.in.SalesRepo.CandiesRanking(ctx, sortBy, opts)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"sort":    sortBy,
+			"options": opts,
+		}))
 
 		c.Status(500).JSON(
 			map[string]string{
@@ -758,47 +4409,67 @@ func (s Service) updateSaleEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(sale)
+	c.Status(200).JSON(ranking)
 }
 
-func (s Service) deleteSaleEndpoint(c *fiber.Ctx) {
-	const opName infra.OpName = "server.deleteSaleEndpoint"
+func (s Service) customersRankingReportEndpoint(c *fiber.Ctx) {
+	const opName infra.OpName = "server.customersRankingReportEndpoint"
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*3)
+	ctx, cancel := s.requestContext(c)
 	defer cancel()
 
-	saleIDParam := c.Params("id")
-	saleID, err := strconv.Atoi(saleIDParam)
-	if err != nil {
-		s.errCh <- errors.New(ctx, err, opName, infra.Metadata{
-			"param": saleIDParam,
-		})
+	payload := customersRankingPayload{}
+	if err := c.QueryParser(&payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"query": c.Fasthttp.QueryArgs().String(),
+		}))
 
-		c.Status(422).JSON(map[string]interface{}{
-			"message": "Invalid sale id.",
-		})
+		c.Status(422).JSON(
+			map[string]string{
+				"message": "Invalid query string.",
+			},
+		)
 
 		return
 	}
 
-	dErr := s.in.SalesRepo.Delete(ctx, infra.ObjectID(saleID))
-	if dErr != nil && errors.Kind(dErr) == infra.KindNotFound {
-		s.errCh <- errors.New(ctx, dErr, opName, infra.Metadata{
-			"param": saleIDParam,
-		})
+	if err := s.in.Validator.Struct(payload); err != nil {
+		s.in.Reporter.Report(errors.New(ctx, err, opName, infra.Metadata{
+			"payload": payload,
+		}))
 
-		c.Status(404).JSON(map[string]interface{}{
-			"message": "The specified sale was not found",
-		})
+		response := handleValidationError(payload, err)
+
+		c.Status(422).JSON(response)
 
 		return
 	}
 
-	if dErr != nil {
-		s.errCh <- errors.New(ctx, dErr, opName, infra.Metadata{
-			"param": saleIDParam,
+	opts, ok := rankingOptions(payload.rankingPayload)
+	if !ok {
+		s.in.Reporter.Report(errors.New(ctx, "Invalid report period.", opName, infra.Metadata{
+			"payload": payload,
+		}))
+
+		c.Status(422).JSON(map[string]interface{}{
+			"message": "Invalid from date, expected YYYY-MM-DD before the to date.",
 		})
 
+		return
+	}
+
+	sortBy := domain.CustomersRankingSort(payload.Sort)
+	if sortBy == "" {
+		sortBy = domain.CustomersBySpend
+	}
+
+	ranking, rErr := s.in.SalesRepo.CustomersRanking(ctx, sortBy, opts)
+	if rErr != nil {
+		s.in.Reporter.Report(errors.New(ctx, rErr, opName, infra.Metadata{
+			"sort":    sortBy,
+			"options": opts,
+		}))
+
 		c.Status(500).JSON(
 			map[string]string{
 				"message": "Internal server error.",
@@ -808,5 +4479,40 @@ func (s Service) deleteSaleEndpoint(c *fiber.Ctx) {
 		return
 	}
 
-	c.Status(200).JSON(map[string]string{"Message": "Sale deleted successfully!"})
+	c.Status(200).JSON(ranking)
+}
+
+// rankingOptions - Fills the ranking period defaults, from the start of the month until today, and the default limit.
+// It isn't ok when the period ends before it starts.
+func rankingOptions(payload rankingPayload) (domain.RankingOptions, bool) {
+	opts := domain.RankingOptions{
+		From:    payload.From,
+		To:      payload.To,
+		Limit:   payload.Limit,
+		Compare: payload.Compare,
+	}
+
+	if opts.To == "" {
+		opts.To = time.Now().Format(dateLayout)
+	}
+
+	to, err := time.Parse(dateLayout, opts.To)
+	if err != nil {
+		return opts, false
+	}
+
+	if opts.From == "" {
+		opts.From = to.AddDate(0, 0, 1-to.Day()).Format(dateLayout)
+	}
+
+	from, err := time.Parse(dateLayout, opts.From)
+	if err != nil || from.After(to) {
+		return opts, false
+	}
+
+	if opts.Limit == 0 {
+		opts.Limit = 10
+	}
+
+	return opts, true
 }
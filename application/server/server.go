@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/cors"
@@ -15,20 +17,40 @@ import (
 
 // ServiceInput ...
 type ServiceInput struct {
-	Log           infra.LogProvider
-	CustomersRepo domain.CustomersRepository
-	CandiesRepo   domain.CandiesRepository
-	SalesRepo     domain.SalesRepository
-	UsersRepo     domain.UsersRepository
-	AuthRepo      domain.AuthRepository
-	Validator     *validator.Validate
-	JwtSecret     string
+	Log             infra.LogProvider
+	CustomersRepo   domain.CustomersRepository
+	CandiesRepo     domain.CandiesRepository
+	IngredientsRepo domain.IngredientsRepository
+	RecipesRepo     domain.RecipesRepository
+	SalesRepo       domain.SalesRepository
+	OrdersRepo      domain.OrdersRepository
+	PaymentsRepo    domain.PaymentsRepository
+	InventoryRepo   domain.InventoryRepository
+	BatchesRepo     domain.BatchesRepository
+	DutiesRepo      domain.DutiesRepository
+	ExpensesRepo    domain.ExpensesRepository
+	UsersRepo       domain.UsersRepository
+	AuthRepo        domain.AuthRepository
+	Reporter        infra.ErrorReporter
+	Validator       *validator.Validate
+	JwtSecret       string
+
+	Address         string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	BodyLimit       int
+	ShutdownTimeout time.Duration
 }
 
 // Service ...
 type Service struct {
-	in    ServiceInput
-	errCh chan *infra.Error
+	in ServiceInput
+
+	// serving is the parent of every request context. It's only canceled when the shutdown deadline
+	// expires, so in-flight requests can finish while the server is draining, each request context
+	// is still canceled on its own when its client goes away.
+	serving context.Context
 }
 
 // NewService ...
@@ -45,14 +67,29 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.Reporter == nil {
+		err := infra.MissingDependencyError{DependencyName: "Reporter"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	if in.JwtSecret == "" {
 		err := infra.MissingDependencyError{DependencyName: "JwtSecret"}
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.Address == "" {
+		err := infra.MissingDependencyError{DependencyName: "Address"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.ShutdownTimeout <= 0 {
+		err := infra.MinimumValueError{EnvVarName: "ShutdownTimeout", MinimumRequired: 1}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	return &Service{
-		in:    in,
-		errCh: make(chan *infra.Error),
+		in:      in,
+		serving: context.Background(),
 	}, nil
 }
 
@@ -61,48 +98,175 @@ func (s Service) Engine(app *fiber.App) {
 	app.Get("/ping", s.pingEndpoint)
 
 	app.Post("/login", s.loginEndpoint)
+	app.Post("/token/refresh", s.refreshTokenEndpoint)
 
 	// JWT Middleware
 	app.Use(jwtware.New(jwtware.Config{
 		SigningKey: []byte(s.in.JwtSecret),
+		ContextKey: tokenLocalsKey,
 	}))
+	app.Use(s.sessionMiddleware)
+
+	app.Post("/logout", s.logoutEndpoint)
+	app.Post("/logout/all", s.logoutAllEndpoint)
+
+	read := s.can(domain.ReadRecords)
+	register := s.can(domain.RegisterSales)
+	manage := s.can(domain.ManageRecords)
+	reports := s.can(domain.ReadReports)
+	users := s.can(domain.ManageUsers)
+
+	app.Get("/user", users, s.listUsersEndpoint)
+	app.Post("/user", users, s.registerUserEndpoint)
+	app.Get("/user/:id", users, s.findUserEndpoint)
+	app.Put("/user/:id", users, s.updateUserEndpoint)
+	app.Post("/user/:id/deactivate", users, s.deactivateUserEndpoint)
+	app.Get("/audit/logins", users, s.listLoginAttemptsEndpoint)
+
+	app.Get("/customer", read, s.listCustomersEndpoint)
+	app.Post("/customer", register, s.registerCustomerEndpoint)
+	app.Get("/customer/:id", read, s.findCustomerEndpoint)
+	app.Put("/customer/:id", register, s.updateCustomerEndpoint)
+	app.Delete("/customer/:id", manage, s.deleteCustomerEndpoint)
+	app.Get("/customer/:id/balance", read, s.customerBalanceEndpoint)
+	app.Get("/customer/:id/statement", read, s.customerStatementEndpoint)
+
+	app.Get("/candy", read, s.listCandiesEndpoint)
+	app.Post("/candy", manage, s.registerCandyEndpoint)
+	app.Get("/candy/:id", read, s.findCandyEndpoint)
+	app.Get("/candy/:id/costs", reports, s.candyCostsEndpoint)
+	app.Put("/candy/:id", manage, s.updateCandyEndpoint)
+	app.Delete("/candy/:id", manage, s.deleteCandyEndpoint)
+	app.Get("/candy/:id/recipe", read, s.findRecipeEndpoint)
+	app.Put("/candy/:id/recipe", manage, s.saveRecipeEndpoint)
+	app.Delete("/candy/:id/recipe", manage, s.deleteRecipeEndpoint)
+
+	app.Get("/ingredient", read, s.listIngredientsEndpoint)
+	app.Post("/ingredient", manage, s.registerIngredientEndpoint)
+	app.Get("/ingredient/:id", read, s.findIngredientEndpoint)
+	app.Put("/ingredient/:id", manage, s.updateIngredientEndpoint)
+	app.Delete("/ingredient/:id", manage, s.deleteIngredientEndpoint)
+
+	app.Post("/shopping-list", read, s.shoppingListEndpoint)
+
+	app.Get("/sale", read, s.listSalesEndpoint)
+	app.Post("/sale", register, s.registerSaleEndpoint)
+	app.Put("/sale/:id", register, s.updateSaleEndpoint)
+	app.Delete("/sale/:id", manage, s.deleteSaleEndpoint)
+	app.Get("/sale/months", read, s.listMonthsThatHasSalesEndpoint)
+	app.Get("/sale/:id", read, s.findSaleEndpoint)
+	app.Get("/sale/:month/:year", reports, s.listMonthSalesEndpoint)
+
+	app.Get("/report/debtors", read, s.debtorsReportEndpoint)
+	app.Get("/report/profit", reports, s.profitReportEndpoint)
+	app.Get("/report/pnl/:month/:year", reports, s.profitAndLossReportEndpoint)
+	app.Get("/report/summary", reports, s.salesSummaryReportEndpoint)
+	app.Get("/report/ranking/candies", reports, s.candiesRankingReportEndpoint)
+	app.Get("/report/ranking/customers", reports, s.customersRankingReportEndpoint)
+
+	app.Post("/order", register, s.registerOrderEndpoint)
+	app.Get("/order/:id", read, s.findOrderEndpoint)
+	app.Put("/order/:id", register, s.updateOrderEndpoint)
+	app.Delete("/order/:id", manage, s.deleteOrderEndpoint)
+
+	app.Get("/inventory", read, s.inventoryEndpoint)
+	app.Post("/inventory/movement", register, s.registerStockMovementEndpoint)
+	app.Get("/inventory/:candyId/movements", read, s.listStockMovementsEndpoint)
+	app.Put("/inventory/:candyId", manage, s.updateLowStockThresholdEndpoint)
 
-	app.Get("/customer", s.listCustomersEndpoint)
-	app.Post("/customer", s.registerCustomerEndpoint)
-	app.Put("/customer/:id", s.updateCustomerEndpoint)
-	app.Delete("/customer/:id", s.deleteCustomerEndpoint)
-
-	app.Get("/candy", s.listCandiesEndpoint)
-	app.Post("/candy", s.registerCandyEndpoint)
-	app.Put("/candy/:id", s.updateCandyEndpoint)
-	app.Delete("/candy/:id", s.deleteCandyEndpoint)
-
-	app.Post("/sale", s.registerSaleEndpoint)
-	app.Put("/sale/:id", s.updateSaleEndpoint)
-	app.Delete("/sale/:id", s.deleteSaleEndpoint)
-	app.Get("/sale/months", s.listMonthsThatHasSalesEndpoint)
-	app.Get("/sale/:month/:year", s.listMonthSalesEndpoint)
+	app.Post("/batch", register, s.registerBatchEndpoint)
+	app.Post("/batch/write-off", manage, s.writeOffExpiredBatchesEndpoint)
+	app.Get("/report/batches/expiring", read, s.expiringBatchesEndpoint)
+	app.Get("/report/batches/written-off", reports, s.writtenOffBatchesEndpoint)
+
+	app.Post("/duty", register, s.openDutyEndpoint)
+	app.Post("/duty/:id/close", register, s.closeDutyEndpoint)
+	app.Get("/duty/:id/summary", read, s.dutySummaryEndpoint)
+
+	app.Get("/expense", reports, s.listExpensesEndpoint)
+	app.Post("/expense", manage, s.registerExpenseEndpoint)
+	app.Get("/expense/:id", reports, s.findExpenseEndpoint)
+	app.Put("/expense/:id", manage, s.updateExpenseEndpoint)
+	app.Delete("/expense/:id", manage, s.deleteExpenseEndpoint)
+
+	app.Post("/payment", register, s.registerPaymentEndpoint)
+	app.Get("/payment/:id", read, s.findPaymentEndpoint)
+	app.Delete("/payment/:id", manage, s.deletePaymentEndpoint)
 }
 
-// Run ...
-func (s Service) Run(ctx context.Context) <-chan *infra.Error {
+// Run - Serves the API until the server fails or ctx is done. On ctx cancellation the server stops accepting
+// connections and waits up to ShutdownTimeout for the in-flight requests, aborting them once it expires.
+func (s Service) Run(ctx context.Context) *infra.Error {
 	const opName infra.OpName = "server.Run"
 
-	app := fiber.New()
+	serving, abort := context.WithCancel(context.Background())
+	defer abort()
 
-	app.Use(requestLogger.New())
+	s.serving = serving
+
+	app := fiber.New(&fiber.Settings{
+		ReadTimeout:  s.in.ReadTimeout,
+		WriteTimeout: s.in.WriteTimeout,
+		IdleTimeout:  s.in.IdleTimeout,
+		BodyLimit:    s.in.BodyLimit,
+	})
+
+	app.Use(s.requestIDMiddleware)
+	app.Use(requestLogger.New(requestLogger.Config{
+		Format: "${time} ${method} ${path} - ${ip} - ${status} - ${latency} - ${header:" + requestIDHeader + "}\n",
+	}))
 	app.Use(cors.New())
 
 	s.Engine(app)
 
+	listener, err := net.Listen("tcp", s.in.Address)
+	if err != nil {
+		return errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	listenErr := make(chan error, 1)
 	go func() {
-		if err := app.Listen(3000); err != nil {
-			s.errCh <- errors.New(opName, err, infra.KindUnexpected)
+		listenErr <- app.Listener(watchedListener{Listener: listener})
+	}()
+
+	s.in.Log.InfoMetadata(ctx, opName, "Server up and running...", infra.Metadata{
+		"address": s.in.Address,
+	})
+
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
 		}
 
-		close(s.errCh)
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.in.Log.Info(ctx, opName, "Shutting down, waiting for the in-flight requests...")
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- app.Shutdown()
 	}()
 
-	s.in.Log.Info(ctx, opName, "Server up and running...")
-	return s.errCh
+	deadline := time.NewTimer(s.in.ShutdownTimeout)
+	defer deadline.Stop()
+
+	select {
+	case err := <-shutdownErr:
+		if err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+	case <-deadline.C:
+		abort()
+
+		return errors.New(ctx, opName, "Timed out waiting for the in-flight requests, aborting them.", infra.KindUnexpected, infra.Metadata{
+			"shutdownTimeout": s.in.ShutdownTimeout.String(),
+		})
+	}
+
+	s.in.Log.Info(ctx, opName, "Server stopped.")
+
+	return nil
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const createAdminUsage = "usage: server create-admin <name> <email>, with the password on the standard input"
+
+// createAdmin - Registers an owner from the terminal, so the first login doesn't need a hand-made hash in the database.
+// The password is read from the input instead of the arguments to keep it out of the shell history, without echoing it
+// when the input is a terminal.
+func createAdmin(ctx context.Context, users domain.UsersRepository, args []string, input io.Reader) *infra.Error {
+	const opName infra.OpName = "cmd/server.createAdmin"
+
+	if len(args) < 2 {
+		return errors.New(ctx, opName, createAdminUsage, infra.KindBadRequest)
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+
+	password, err := readPassword(input)
+	if err != nil {
+		return errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	if len(password) < 8 {
+		return errors.New(ctx, opName, "The password must have at least 8 characters.", infra.KindBadRequest)
+	}
+
+	user, uErr := users.Register(ctx, domain.User{
+		Name:     args[0],
+		Email:    args[1],
+		Password: password,
+		Role:     domain.Owner,
+	})
+
+	if uErr != nil {
+		return errors.New(ctx, opName, uErr)
+	}
+
+	fmt.Printf("User %d created for %s.\n", user.ID, user.Email)
+
+	return nil
+}
+
+// readPassword - Reads a line of the input, with the echo off when it's a terminal
+func readPassword(input io.Reader) (string, error) {
+	if file, ok := input.(*os.File); ok && terminal.IsTerminal(int(file.Fd())) {
+		password, err := terminal.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(os.Stderr)
+
+		return string(password), err
+	}
+
+	password, err := bufio.NewReader(input).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimRight(password, "\r\n"), nil
+}
@@ -4,13 +4,25 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/lucasmls/backend-cacautime/application/server"
+	"github.com/lucasmls/backend-cacautime/domain"
 	"github.com/lucasmls/backend-cacautime/domain/auth"
+	"github.com/lucasmls/backend-cacautime/domain/batches"
 	"github.com/lucasmls/backend-cacautime/domain/candies"
 	"github.com/lucasmls/backend-cacautime/domain/customers"
+	"github.com/lucasmls/backend-cacautime/domain/duties"
+	"github.com/lucasmls/backend-cacautime/domain/expenses"
+	"github.com/lucasmls/backend-cacautime/domain/ingredients"
+	"github.com/lucasmls/backend-cacautime/domain/inventory"
+	"github.com/lucasmls/backend-cacautime/domain/orders"
+	"github.com/lucasmls/backend-cacautime/domain/payments"
+	"github.com/lucasmls/backend-cacautime/domain/recipes"
 	"github.com/lucasmls/backend-cacautime/domain/sales"
 	"github.com/lucasmls/backend-cacautime/domain/users"
 	"github.com/lucasmls/backend-cacautime/infra"
@@ -18,7 +30,9 @@ import (
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 	"github.com/lucasmls/backend-cacautime/infra/jwt"
 	"github.com/lucasmls/backend-cacautime/infra/log"
+	"github.com/lucasmls/backend-cacautime/infra/migrations"
 	"github.com/lucasmls/backend-cacautime/infra/postgres"
+	"github.com/lucasmls/backend-cacautime/infra/reporter"
 )
 
 type config struct {
@@ -26,8 +40,23 @@ type config struct {
 	logLevel             string
 	dbConnectionString   string
 	dbMaxConnectionsOpen int
+	dbTxIsolationLevel   string
+	dbTxMaxRetries       int
 	jwtSecret            string
 	jwtExpirationInHours int
+	refreshTTLInDays     int
+	migrationsDir        string
+	errorsQueueSize      int
+	errorsFilePath       string
+	errorsCollectorURL   string
+	inventoryOversell    string
+
+	serverAddress                  string
+	serverReadTimeoutInSeconds     int
+	serverWriteTimeoutInSeconds    int
+	serverIdleTimeoutInSeconds     int
+	serverBodyLimitInBytes         int
+	serverShutdownTimeoutInSeconds int
 }
 
 func env() (*config, *infra.Error) {
@@ -36,8 +65,22 @@ func env() (*config, *infra.Error) {
 	c := &config{
 		goEnv:              infra.Environment(os.Getenv("GO_ENV")),
 		dbConnectionString: os.Getenv("DB_CONNECTION_STRING"),
+		dbTxIsolationLevel: os.Getenv("DB_TX_ISOLATION_LEVEL"),
 		jwtSecret:          os.Getenv("JWT_SECRET"),
 		logLevel:           os.Getenv("LOG_LEVEL"),
+		migrationsDir:      os.Getenv("MIGRATIONS_DIR"),
+		errorsFilePath:     os.Getenv("ERRORS_FILE_PATH"),
+		errorsCollectorURL: os.Getenv("ERRORS_COLLECTOR_URL"),
+		serverAddress:      os.Getenv("SERVER_ADDRESS"),
+		inventoryOversell:  os.Getenv("INVENTORY_OVERSELL_POLICY"),
+	}
+
+	if c.migrationsDir == "" {
+		c.migrationsDir = "sql"
+	}
+
+	if c.serverAddress == "" {
+		c.serverAddress = ":3000"
 	}
 
 	dbMaxConnectionsOpen, err := strconv.Atoi(os.Getenv("DB_MAX_CONNECTIONS_OPEN"))
@@ -47,6 +90,39 @@ func env() (*config, *infra.Error) {
 
 	c.dbMaxConnectionsOpen = dbMaxConnectionsOpen
 
+	optionalInts := []struct {
+		name     string
+		value    *int
+		fallback int
+	}{
+		{"DB_TX_MAX_RETRIES", &c.dbTxMaxRetries, 3},
+		{"ERRORS_QUEUE_SIZE", &c.errorsQueueSize, 1024},
+		{"REFRESH_TOKEN_EXPIRATION_IN_DAYS", &c.refreshTTLInDays, 30},
+		{"SERVER_READ_TIMEOUT_IN_SECONDS", &c.serverReadTimeoutInSeconds, 30},
+		{"SERVER_WRITE_TIMEOUT_IN_SECONDS", &c.serverWriteTimeoutInSeconds, 30},
+		{"SERVER_IDLE_TIMEOUT_IN_SECONDS", &c.serverIdleTimeoutInSeconds, 120},
+		{"SERVER_BODY_LIMIT_IN_BYTES", &c.serverBodyLimitInBytes, 4 * 1024 * 1024},
+		{"SERVER_SHUTDOWN_TIMEOUT_IN_SECONDS", &c.serverShutdownTimeoutInSeconds, 30},
+	}
+
+	for _, optional := range optionalInts {
+		*optional.value = optional.fallback
+
+		value := os.Getenv(optional.name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New(err, opName, infra.KindBadRequest, infra.Metadata{
+				"envVar": optional.name,
+			})
+		}
+
+		*optional.value = parsed
+	}
+
 	jwtExpirationInHours, err := strconv.Atoi(os.Getenv("JWT_EXPIRATION_IN_HOURS"))
 	if err != nil {
 		return nil, errors.New(err, opName, infra.KindBadRequest)
@@ -58,6 +134,8 @@ func env() (*config, *infra.Error) {
 }
 
 func main() {
+	const opName infra.OpName = "cmd/server.main"
+
 	ctx := context.Background()
 
 	env, err := env()
@@ -80,6 +158,19 @@ func main() {
 		Log:                log,
 		ConnectionString:   env.dbConnectionString,
 		MaxConnectionsOpen: env.dbMaxConnectionsOpen,
+		TxIsolationLevel:   infra.TxIsolationLevel(env.dbTxIsolationLevel),
+		TxMaxRetries:       env.dbTxMaxRetries,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	migrator, err := migrations.NewClient(migrations.ClientInput{
+		Log: log,
+		Db:  postgres,
+		Dir: env.migrationsDir,
 	})
 
 	if err != nil {
@@ -87,6 +178,29 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(ctx, migrator, os.Args[2:]); err != nil {
+			errors.Log(log, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	pendingMigrations, err := migrator.Pending(ctx)
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	if len(pendingMigrations) > 0 {
+		log.CriticalMetadata(ctx, opName, "The database schema is behind, run `server migrate up` before starting the server.", infra.Metadata{
+			"pendingMigrations": len(pendingMigrations),
+			"nextVersion":       pendingMigrations[0].Version,
+		})
+		return
+	}
+
 	bcrypt, err := bcrypt.NewClient(bcrypt.ClientInput{
 		Log: log,
 	})
@@ -127,7 +241,85 @@ func main() {
 		return
 	}
 
+	recipesR, err := recipes.NewService(recipes.ServiceInput{
+		Db:      postgres,
+		Log:     log,
+		Candies: candiesR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	ingredientsR, err := ingredients.NewService(ingredients.ServiceInput{
+		Db:      postgres,
+		Log:     log,
+		Recipes: recipesR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	inventoryR, err := inventory.NewService(inventory.ServiceInput{
+		Db:       postgres,
+		Log:      log,
+		Oversell: domain.OversellPolicy(env.inventoryOversell),
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	batchesR, err := batches.NewService(batches.ServiceInput{
+		Db:        postgres,
+		Log:       log,
+		Inventory: inventoryR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	ordersR, err := orders.NewService(orders.ServiceInput{
+		Db:        postgres,
+		Log:       log,
+		Inventory: inventoryR,
+		Batches:   batchesR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
 	salesR, err := sales.NewService(sales.ServiceInput{
+		Db:     postgres,
+		Log:    log,
+		Orders: ordersR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	expensesR, err := expenses.NewService(expenses.ServiceInput{
+		Db:    postgres,
+		Log:   log,
+		Sales: salesR,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	paymentsR, err := payments.NewService(payments.ServiceInput{
 		Db:  postgres,
 		Log: log,
 	})
@@ -137,9 +329,9 @@ func main() {
 		return
 	}
 
-	usersR, err := users.NewService(users.ServiceInput{
-		Log: log,
+	dutiesR, err := duties.NewService(duties.ServiceInput{
 		Db:  postgres,
+		Log: log,
 	})
 
 	if err != nil {
@@ -147,11 +339,50 @@ func main() {
 		return
 	}
 
-	authR, err := auth.NewService(auth.ServiceInput{
+	usersR, err := users.NewService(users.ServiceInput{
 		Log:    log,
-		Users:  usersR,
+		Db:     postgres,
 		Crypto: bcrypt,
-		JWT:    jwt,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		if err := createAdmin(ctx, usersR, os.Args[2:], os.Stdin); err != nil {
+			errors.Log(log, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	authR, err := auth.NewService(auth.ServiceInput{
+		Log:        log,
+		Db:         postgres,
+		Users:      usersR,
+		Crypto:     bcrypt,
+		JWT:        jwt,
+		RefreshTTL: env.refreshTTLInDays,
+	})
+
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	errorSinks, err := sinks(log, env)
+	if err != nil {
+		errors.Log(log, err)
+		return
+	}
+
+	errorReporter, err := reporter.NewClient(reporter.ClientInput{
+		Log:       log,
+		Sinks:     errorSinks,
+		QueueSize: env.errorsQueueSize,
 	})
 
 	if err != nil {
@@ -160,14 +391,30 @@ func main() {
 	}
 
 	s, err := server.NewService(server.ServiceInput{
-		Log:           log,
-		CustomersRepo: customers,
-		CandiesRepo:   candiesR,
-		SalesRepo:     salesR,
-		UsersRepo:     usersR,
-		AuthRepo:      authR,
-		Validator:     validator.New(),
-		JwtSecret:     env.jwtSecret,
+		Log:             log,
+		CustomersRepo:   customers,
+		CandiesRepo:     candiesR,
+		IngredientsRepo: ingredientsR,
+		RecipesRepo:     recipesR,
+		SalesRepo:       salesR,
+		OrdersRepo:      ordersR,
+		PaymentsRepo:    paymentsR,
+		InventoryRepo:   inventoryR,
+		BatchesRepo:     batchesR,
+		DutiesRepo:      dutiesR,
+		ExpensesRepo:    expensesR,
+		Reporter:        errorReporter,
+		UsersRepo:       usersR,
+		AuthRepo:        authR,
+		Validator:       validator.New(),
+		JwtSecret:       env.jwtSecret,
+
+		Address:         env.serverAddress,
+		ReadTimeout:     time.Duration(env.serverReadTimeoutInSeconds) * time.Second,
+		WriteTimeout:    time.Duration(env.serverWriteTimeoutInSeconds) * time.Second,
+		IdleTimeout:     time.Duration(env.serverIdleTimeoutInSeconds) * time.Second,
+		BodyLimit:       env.serverBodyLimitInBytes,
+		ShutdownTimeout: time.Duration(env.serverShutdownTimeoutInSeconds) * time.Second,
 	})
 
 	if err != nil {
@@ -175,9 +422,75 @@ func main() {
 		return
 	}
 
-	ch := s.Run(ctx)
-	for err := range ch {
+	serveCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.InfoMetadata(ctx, opName, "Received a shutdown signal.", infra.Metadata{
+			"signal": sig.String(),
+		})
+
+		stop()
+	}()
+
+	if err := s.Run(serveCtx); err != nil {
+		errors.Log(log, err)
+	}
+
+	// The handlers are done by now, so the pending errors are flushed before the database goes away.
+	closeCtx, cancel := context.WithTimeout(ctx, time.Duration(env.serverShutdownTimeoutInSeconds)*time.Second)
+	defer cancel()
+
+	if err := errorReporter.Close(closeCtx); err != nil {
 		errors.Log(log, err)
-		// @TODO -> Add some monitoring metrics here and in the whole application.
 	}
+
+	if err := postgres.Close(closeCtx); err != nil {
+		errors.Log(log, err)
+	}
+
+	log.Info(ctx, opName, "Shutdown complete.")
+}
+
+// sinks - Builds the destinations of the errors reported while serving requests
+func sinks(log infra.LogProvider, env *config) ([]infra.ErrorSink, *infra.Error) {
+	logSink, err := reporter.NewLogSink(reporter.LogSinkInput{
+		Log: log,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	errorSinks := []infra.ErrorSink{logSink}
+
+	if env.errorsFilePath != "" {
+		fileSink, err := reporter.NewFileSink(reporter.FileSinkInput{
+			Path: env.errorsFilePath,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		errorSinks = append(errorSinks, fileSink)
+	}
+
+	if env.errorsCollectorURL != "" {
+		httpSink, err := reporter.NewHTTPSink(reporter.HTTPSinkInput{
+			URL: env.errorsCollectorURL,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		errorSinks = append(errorSinks, httpSink)
+	}
+
+	return errorSinks, nil
 }
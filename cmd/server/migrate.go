@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+	"github.com/lucasmls/backend-cacautime/infra/migrations"
+)
+
+const migrateUsage = "usage: server migrate <up|down|status|to <version>|baseline <version>>"
+
+func migrate(ctx context.Context, migrator *migrations.Client, args []string) *infra.Error {
+	const opName infra.OpName = "cmd/server.migrate"
+
+	if len(args) < 1 {
+		return errors.New(ctx, opName, migrateUsage, infra.KindBadRequest)
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt
+			}
+
+			fmt.Printf("%03d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return nil
+	case "to", "baseline":
+		if len(args) < 2 {
+			return errors.New(ctx, opName, migrateUsage, infra.KindBadRequest)
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(ctx, opName, err, infra.KindBadRequest, infra.Metadata{
+				"version": args[1],
+			})
+		}
+
+		if args[0] == "baseline" {
+			return migrator.Baseline(ctx, version)
+		}
+
+		return migrator.To(ctx, version)
+	}
+
+	return errors.New(ctx, opName, migrateUsage, infra.KindBadRequest)
+}
@@ -2,19 +2,68 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
 	"github.com/lucasmls/backend-cacautime/infra"
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+// refreshTokenSize is how many random bytes make a refresh token
+const refreshTokenSize = 32
+
+const (
+	// accountFreeFailures is how many logins an e-mail can fail in a row before it has to wait
+	accountFreeFailures = 5
+	// ipFreeFailures is how many logins an IP can fail in an hour before it has to wait, it's higher
+	// because many people may share the IP
+	ipFreeFailures = 20
+	// backoffBase is the wait after the first failure that isn't free, it doubles on every failure after it
+	backoffBase = time.Second * 15
+	// lockoutDuration is the longest wait, once it's reached the account or IP is locked out for that long
+	lockoutDuration = time.Minute * 15
+)
+
+// unknownUserHash is checked when the e-mail is unknown, so it takes as long as a wrong password
+const unknownUserHash = "$2a$10$szMdvXYoF.PI8Hcf5SHXdu095tq7nY3csZdQeV9i3Z6HTyiE3zUXS"
+
+// loginAttemptColumns are the columns decoded into domain.LoginAttempt, from login_attempts (a)
+const loginAttemptColumns = `
+	a.id as id,
+	a.email as email,
+	a.ip as ip,
+	COALESCE(a.user_id, 0) as userId,
+	a.result as result,
+	to_char(a.created_at, 'YYYY-MM-DD"T"HH24:MI:SS') as createdAt
+`
+
+var loginAttemptsSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":        {Expr: "a.id", Type: "integer"},
+		"createdAt": {Expr: "a.created_at", Type: "timestamp"},
+	},
+	DefaultSort:      "createdAt",
+	DefaultDirection: domain.Descending,
+	ID:               "a.id",
+}
+
 // ServiceInput ...
 type ServiceInput struct {
 	Log    infra.LogProvider
+	Db     infra.RelationalDatabaseProvider
 	Crypto infra.CryptoProvider
 	Users  domain.UsersRepository
 	JWT    infra.TokenProvider
+
+	// RefreshTTL is how many days a session can be refreshed for before logging in again
+	RefreshTTL int
 }
 
 // Service ...
@@ -24,13 +73,18 @@ type Service struct {
 
 // NewService ...
 func NewService(in ServiceInput) (*Service, *infra.Error) {
-	const opName infra.OpName = "candies.NewService"
+	const opName infra.OpName = "auth.NewService"
 
 	if in.Log == nil {
 		err := infra.MissingDependencyError{DependencyName: "Log"}
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	if in.Crypto == nil {
 		err := infra.MissingDependencyError{DependencyName: "Crypto"}
 		return nil, errors.New(err, opName, infra.KindBadRequest)
@@ -46,28 +100,590 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.RefreshTTL < 1 {
+		err := infra.MinimumValueError{EnvVarName: "RefreshTTL", MinimumRequired: 1}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	return &Service{
 		in: in,
 	}, nil
 }
 
-// Login ...
-func (s Service) Login(ctx context.Context, email string, password string) (string, *infra.Error) {
+// Login - Checks the password and starts a session for the user. Unknown e-mails and wrong passwords fail alike,
+// as unauthorized, and too many failures from the e-mail or the IP fail with too many requests for a while.
+// The attempts of the e-mail run one at a time, so they can't all get past the lockout together. The IP is only
+// locked while its attempt is reserved, so the logins sharing it don't wait on each other's password checks.
+func (s Service) Login(ctx context.Context, email string, password string, ip string) (*domain.Tokens, *infra.Error) {
 	const opName infra.OpName = "auth.Login"
 
-	user, err := s.in.Users.FindByEmail(ctx, email)
+	query := `
+		INSERT INTO sessions (user_id, expires_at) VALUES ($1, now() + make_interval(days => $2))
+		RETURNING id
+	`
+
+	attemptID, retryAfter, err := s.reserveAttempt(ctx, email, ip)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	if retryAfter > 0 {
+		return nil, tooManyFailures(ctx, opName, retryAfter)
+	}
+
+	var tokens *domain.Tokens
+	var failure *infra.Error
+
+	// Read committed, so the lockout sees the attempts committed while waiting for the lock
+	txOptions := infra.TxOptions{Isolation: infra.TxReadCommitted}
+
+	err = s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+		tokens, failure = nil, nil
+
+		if err := txService.lockEmail(ctx, email); err != nil {
+			return err
+		}
+
+		retryAfter, err := txService.accountLockout(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		// The failed attempts are committed, their errors are only returned after the transaction
+		if retryAfter > 0 {
+			failure = tooManyFailures(ctx, opName, retryAfter)
+
+			return txService.finishAttempt(ctx, attemptID, 0, domain.LoginLocked)
+		}
+
+		user, err := s.in.Users.FindByEmail(ctx, email)
+		if err != nil && errors.Kind(err) == infra.KindNotFound {
+			// The result doesn't matter, only the time it takes
+			_ = s.in.Crypto.Compare(ctx, unknownUserHash, password)
+
+			failure = errors.New(ctx, opName, "Wrong e-mail or password.", infra.KindUnauthorized)
+
+			return txService.finishAttempt(ctx, attemptID, 0, domain.LoginUnknownEmail)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.in.Crypto.Compare(ctx, user.Password, password); err != nil {
+			if errors.Kind(err) != infra.KindUnauthorized {
+				return err
+			}
+
+			failure = errors.New(ctx, opName, err)
+
+			return txService.finishAttempt(ctx, attemptID, user.ID, domain.LoginWrongPassword)
+		}
+
+		session := struct{ ID infra.ObjectID }{}
+		if err := tx.Query(ctx, query, user.ID, s.in.RefreshTTL).Decode(ctx, &session); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		issued, err := s.issue(ctx, tx, *user, session.ID)
+		if err != nil {
+			return err
+		}
+
+		tokens = issued
+
+		return txService.finishAttempt(ctx, attemptID, user.ID, domain.LoginSucceeded)
+	}, txOptions)
+
 	if err != nil {
-		return "", errors.New(ctx, opName, err)
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	if failure != nil {
+		return nil, failure
 	}
 
-	if err := s.in.Crypto.Compare(ctx, user.Password, password); err != nil {
-		return "", errors.New(ctx, opName, err)
+	return tokens, nil
+}
+
+// Refresh - Exchanges the refresh token for new tokens, the refresh token can't be used again.
+// Using it again means it leaked, so the whole session is revoked.
+func (s Service) Refresh(ctx context.Context, refreshToken string) (*domain.Tokens, *infra.Error) {
+	const opName infra.OpName = "auth.Refresh"
+
+	query := `
+		SELECT
+			rt.id as id,
+			rt.used_at IS NOT NULL as used,
+			se.id as sessionId,
+			se.user_id as userId,
+			se.revoked_at IS NULL AND se.expires_at > now() as active
+		FROM
+			refresh_tokens rt
+			INNER JOIN sessions se ON se.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, se
+	`
+
+	useQuery := `UPDATE refresh_tokens SET used_at = now() WHERE id = $1`
+
+	s.in.Log.Info(ctx, opName, "Refreshing the tokens...")
+
+	var tokens *domain.Tokens
+	reused := false
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		current := struct {
+			ID        infra.ObjectID
+			Used      bool
+			SessionID infra.ObjectID
+			UserID    infra.ObjectID
+			Active    bool
+		}{}
+
+		if err := tx.Query(ctx, query, hashToken(refreshToken)).Decode(ctx, &current); err != nil {
+			if errors.Kind(err) == infra.KindNotFound {
+				return errors.New(ctx, opName, "Invalid refresh token.", infra.KindUnauthorized)
+			}
+
+			return errors.New(ctx, opName, err)
+		}
+
+		if current.Used {
+			// The revocation is committed, the error is only returned after the transaction
+			reused = true
+
+			s.in.Log.WarningMetadata(ctx, opName, "A used refresh token was presented, revoking its session...", infra.Metadata{
+				"sessionID": current.SessionID,
+				"userID":    current.UserID,
+			})
+
+			return s.revoke(ctx, tx, `WHERE id = $1`, current.SessionID)
+		}
+
+		if !current.Active {
+			return errors.New(ctx, opName, "The session has ended.", infra.KindUnauthorized)
+		}
+
+		if _, err := tx.Execute(ctx, useQuery, current.ID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		user, err := s.in.Users.Find(ctx, current.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !user.Active {
+			return errors.New(ctx, opName, "The user is inactive.", infra.KindUnauthorized)
+		}
+
+		issued, err := s.issue(ctx, tx, *user, current.SessionID)
+		if err != nil {
+			return err
+		}
+
+		tokens = issued
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	if reused {
+		return nil, errors.New(ctx, opName, "The refresh token was already used.", infra.KindUnauthorized)
+	}
+
+	return tokens, nil
+}
+
+// Logout - Revokes the session, its tokens stop working
+func (s Service) Logout(ctx context.Context, sessionID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "auth.Logout"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Logging out...", infra.Metadata{
+		"sessionID": sessionID,
+	})
+
+	if err := s.revoke(ctx, s.in.Db, `WHERE id = $1`, sessionID); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// LogoutAll - Revokes every session of the user, e.g. when a device was lost
+func (s Service) LogoutAll(ctx context.Context, userID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "auth.LogoutAll"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Logging out all the sessions...", infra.Metadata{
+		"userID": userID,
+	})
+
+	if err := s.revoke(ctx, s.in.Db, `WHERE user_id = $1`, userID); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// SessionActive - Whether the session exists and wasn't revoked
+func (s Service) SessionActive(ctx context.Context, sessionID infra.ObjectID) (bool, *infra.Error) {
+	const opName infra.OpName = "auth.SessionActive"
+
+	query := `SELECT COUNT(*) as sessions FROM sessions WHERE id = $1 AND revoked_at IS NULL`
+
+	active := struct{ Sessions int }{}
+	if err := s.in.Db.Query(ctx, query, sessionID).Decode(ctx, &active); err != nil {
+		return false, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	return active.Sessions > 0, nil
+}
+
+// LoginAttempts - Lists a page of the login attempts matching the filter, searching by e-mail
+func (s Service) LoginAttempts(ctx context.Context, filter domain.LoginAttemptsFilter, opts domain.QueryOptions) (*domain.LoginAttemptsPage, *infra.Error) {
+	const opName infra.OpName = "auth.LoginAttempts"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the login attempts...", infra.Metadata{
+		"filter":  filter,
+		"options": opts,
+	})
+
+	builder := pagination.Builder{}
+	builder.Search(opts.Search, "a.email")
+
+	if filter.From != "" {
+		builder.Where("a.created_at >= " + builder.Arg(filter.From) + "::date")
+	}
+
+	if filter.To != "" {
+		builder.Where("a.created_at < " + builder.Arg(filter.To) + "::date + 1")
+	}
+
+	if filter.Email != "" {
+		builder.Where("lower(a.email) = lower(" + builder.Arg(filter.Email) + ")")
+	}
+
+	if filter.IP != "" {
+		builder.Where("a.ip = " + builder.Arg(filter.IP))
+	}
+
+	if filter.Result != "" {
+		builder.Where("a.result = " + builder.Arg(filter.Result))
+	}
+
+	page, err := builder.Page(ctx, opts, loginAttemptsSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	totalsQuery := `SELECT COUNT(*) as total FROM login_attempts a ` + builder.Filter()
+
+	query := `
+		SELECT ` + loginAttemptColumns + `,
+			` + page.CursorValue + ` as cursorValue
+		FROM
+			login_attempts a
+	` + page.Where + page.OrderBy
+
+	attempts := domain.LoginAttemptsPage{}
+	if err := s.in.Db.Query(ctx, totalsQuery, builder.Args()...).Decode(ctx, &attempts); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 	}
 
-	jwt, err := s.in.JWT.Generate(ctx, fmt.Sprintf("%d", user.ID))
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
 	if err != nil {
-		return "", errors.New(ctx, opName, err)
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 	}
 
-	return jwt, nil
+	defer cursor.Close(ctx)
+
+	attempts.Data = []domain.LoginAttempt{}
+
+	lastCursorValue := ""
+
+	for cursor.Next(ctx) {
+		row := struct {
+			domain.LoginAttempt
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if len(attempts.Data) == page.Limit {
+			last := attempts.Data[len(attempts.Data)-1]
+			attempts.NextCursor = page.NextCursor(lastCursorValue, last.ID)
+			break
+		}
+
+		attempts.Data = append(attempts.Data, row.LoginAttempt)
+		lastCursorValue = row.CursorValue
+	}
+
+	return &attempts, nil
+}
+
+// withDb - Copies the service to query through db, so the attempts are counted and recorded under the login locks
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// lockEmail - Holds the logins of the e-mail until the transaction ends
+func (s Service) lockEmail(ctx context.Context, email string) *infra.Error {
+	const opName infra.OpName = "auth.lockEmail"
+
+	query := `SELECT pg_advisory_xact_lock(1, hashtext(lower($1)))`
+
+	if _, err := s.in.Db.Execute(ctx, query, email); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// reserveAttempt - Records the attempt as pending, or as locked when the IP has to wait, returning how long it has to.
+// It takes its own transaction holding the IP lock, which is only needed to count the failures and record the
+// attempt together. The pending attempts count as failures of the IP until they're finished, and the ones that never
+// are, because their login failed unexpectedly, keep counting until they're older than the hour.
+func (s Service) reserveAttempt(ctx context.Context, email string, ip string) (infra.ObjectID, time.Duration, *infra.Error) {
+	const opName infra.OpName = "auth.reserveAttempt"
+
+	lockQuery := `SELECT pg_advisory_xact_lock(2, hashtext($1))`
+
+	var attemptID infra.ObjectID
+	var retryAfter time.Duration
+
+	// Read committed, so the lockout sees the attempts committed while waiting for the lock
+	txOptions := infra.TxOptions{Isolation: infra.TxReadCommitted}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+		attemptID, retryAfter = 0, 0
+
+		if _, err := tx.Execute(ctx, lockQuery, ip); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		wait, err := txService.ipLockout(ctx, ip)
+		if err != nil {
+			return err
+		}
+
+		if wait > 0 {
+			retryAfter = wait
+
+			_, err := txService.recordAttempt(ctx, email, ip, domain.LoginLocked)
+			return err
+		}
+
+		attemptID, err = txService.recordAttempt(ctx, email, ip, domain.LoginPending)
+		return err
+	}, txOptions)
+
+	if err != nil {
+		return 0, 0, errors.New(ctx, opName, err)
+	}
+
+	return attemptID, retryAfter, nil
+}
+
+// accountLockout - How long the e-mail still has to wait before trying again, 0 when it doesn't.
+// A success resets the e-mail failures.
+func (s Service) accountLockout(ctx context.Context, email string) (time.Duration, *infra.Error) {
+	const opName infra.OpName = "auth.accountLockout"
+
+	query := `
+		SELECT
+			COUNT(*) as failures,
+			COALESCE(EXTRACT(EPOCH FROM now() - MAX(a.created_at)), 0) as elapsed
+		FROM
+			login_attempts a
+		WHERE
+			lower(a.email) = lower($1)
+			AND a.result IN ('unknown_email', 'wrong_password')
+			AND a.created_at > now() - interval '1 day'
+			AND a.created_at > COALESCE((
+				SELECT MAX(l.created_at) FROM login_attempts l WHERE lower(l.email) = lower($1) AND l.result = 'success'
+			), '-infinity')
+	`
+
+	retryAfter, err := s.lockout(ctx, query, email, accountFreeFailures)
+	if err != nil {
+		return 0, errors.New(ctx, opName, err)
+	}
+
+	return retryAfter, nil
+}
+
+// ipLockout - How long the IP still has to wait before trying again, 0 when it doesn't. The pending attempts
+// count as failures, and a success doesn't reset them, or logging into any account would.
+func (s Service) ipLockout(ctx context.Context, ip string) (time.Duration, *infra.Error) {
+	const opName infra.OpName = "auth.ipLockout"
+
+	query := `
+		SELECT
+			COUNT(*) as failures,
+			COALESCE(EXTRACT(EPOCH FROM now() - MAX(a.created_at)), 0) as elapsed
+		FROM
+			login_attempts a
+		WHERE
+			a.ip = $1
+			AND a.result IN ('unknown_email', 'wrong_password', 'pending')
+			AND a.created_at > now() - interval '1 hour'
+	`
+
+	retryAfter, err := s.lockout(ctx, query, ip, ipFreeFailures)
+	if err != nil {
+		return 0, errors.New(ctx, opName, err)
+	}
+
+	return retryAfter, nil
+}
+
+// lockout - How long to wait after the failures counted by the query, the free ones don't make anyone wait
+func (s Service) lockout(ctx context.Context, query string, arg string, free int) (time.Duration, *infra.Error) {
+	const opName infra.OpName = "auth.lockout"
+
+	failures := struct {
+		Failures int
+		Elapsed  float64
+	}{}
+
+	if err := s.in.Db.Query(ctx, query, arg).Decode(ctx, &failures); err != nil {
+		return 0, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	elapsed := time.Duration(failures.Elapsed * float64(time.Second))
+
+	if wait := backoff(failures.Failures, free) - elapsed; wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+// recordAttempt - Keeps the attempt for the lockout and the audit, the user is only known once it's finished
+func (s Service) recordAttempt(ctx context.Context, email string, ip string, result domain.LoginResult) (infra.ObjectID, *infra.Error) {
+	const opName infra.OpName = "auth.recordAttempt"
+
+	query := `INSERT INTO login_attempts (email, ip, result) VALUES ($1, $2, $3) RETURNING id`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Recording a login attempt...", infra.Metadata{
+		"email":  email,
+		"ip":     ip,
+		"result": result,
+	})
+
+	attempt := struct{ ID infra.ObjectID }{}
+	if err := s.in.Db.Query(ctx, query, email, ip, result).Decode(ctx, &attempt); err != nil {
+		return 0, errors.New(ctx, opName, err)
+	}
+
+	return attempt.ID, nil
+}
+
+// finishAttempt - Records the result of the pending attempt, userID is 0 when the e-mail is unknown
+func (s Service) finishAttempt(ctx context.Context, attemptID infra.ObjectID, userID infra.ObjectID, result domain.LoginResult) *infra.Error {
+	const opName infra.OpName = "auth.finishAttempt"
+
+	query := `UPDATE login_attempts SET user_id = NULLIF($2, 0), result = $3 WHERE id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Finishing a login attempt...", infra.Metadata{
+		"attemptID": attemptID,
+		"result":    result,
+	})
+
+	if _, err := s.in.Db.Execute(ctx, query, attemptID, userID, result); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// tooManyFailures - The error of a login made while the e-mail or the IP has to wait
+func tooManyFailures(ctx context.Context, opName infra.OpName, retryAfter time.Duration) *infra.Error {
+	return errors.New(ctx, opName, "Too many failed logins.", infra.KindTooManyRequests, infra.Metadata{
+		"retryAfter": int(math.Ceil(retryAfter.Seconds())),
+	})
+}
+
+// backoff - How long to wait after the last of the failures. Nothing until the free ones are spent, then
+// backoffBase doubling on every failure, up to lockoutDuration.
+func backoff(failures int, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	wait := backoffBase
+	for i := free; i < failures && wait < lockoutDuration; i++ {
+		wait *= 2
+	}
+
+	if wait > lockoutDuration {
+		return lockoutDuration
+	}
+
+	return wait
+}
+
+// issue - Generates the access token for the session, and the refresh token that replaces the previous one
+func (s Service) issue(ctx context.Context, db infra.RelationalDatabaseProvider, user domain.User, sessionID infra.ObjectID) (*domain.Tokens, *infra.Error) {
+	const opName infra.OpName = "auth.issue"
+
+	query := `INSERT INTO refresh_tokens (session_id, token_hash) VALUES ($1, $2)`
+
+	random := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(random)
+
+	if _, err := db.Execute(ctx, query, sessionID, hashToken(refreshToken)); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	accessToken, err := s.in.JWT.Generate(ctx, infra.TokenClaims{
+		UserID:    fmt.Sprintf("%d", user.ID),
+		Role:      string(user.Role),
+		SessionID: fmt.Sprintf("%d", sessionID),
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &domain.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// revoke - Revokes the sessions matching the condition that are still active
+func (s Service) revoke(ctx context.Context, db infra.RelationalDatabaseProvider, condition string, arg interface{}) *infra.Error {
+	const opName infra.OpName = "auth.revoke"
+
+	query := `UPDATE sessions SET revoked_at = now() ` + condition + ` AND revoked_at IS NULL`
+
+	if _, err := db.Execute(ctx, query, arg); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// hashToken - Refresh tokens are random enough for a plain hash, which unlike bcrypt can be looked up
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
 }
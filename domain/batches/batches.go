@@ -0,0 +1,456 @@
+package batches
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// batchColumns are the columns decoded into domain.Batch, from batches (b) joined with candies (ca)
+const batchColumns = `
+	b.id as id,
+	b.candy_id as candyId,
+	ca.name as candyName,
+	b.produced as produced,
+	b.remaining as remaining,
+	b.written_off as writtenOff,
+	b.production_date::text as productionDate,
+	b.expiry_date::text as expiryDate,
+	COALESCE(b.written_off_at::text, '') as writtenOffAt,
+	b.expiry_date - CURRENT_DATE as daysToExpiry
+`
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db        infra.RelationalDatabaseProvider
+	Log       infra.LogProvider
+	Inventory domain.InventoryRepository
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "batches.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Inventory == nil {
+		err := infra.MissingDependencyError{DependencyName: "Inventory"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// WithDb - Copies the batches to query through db, so they are consumed and restored in the transaction of the order
+func (s Service) WithDb(db infra.RelationalDatabaseProvider) domain.BatchesRepository {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Register - Registers the batch and adds its units to the candy stock
+func (s Service) Register(ctx context.Context, batchDTO domain.Batch) (*domain.Batch, *infra.Error) {
+	const opName infra.OpName = "batches.Register"
+
+	if batchDTO.Produced < 1 {
+		return nil, errors.New(ctx, opName, "The batch must produce at least one unit.", infra.KindBadRequest)
+	}
+
+	insertQuery := `
+		INSERT INTO batches (candy_id, produced, remaining, production_date, expiry_date)
+		VALUES ($1, $2, $2, $3, $4)
+		RETURNING id
+	`
+
+	query := `SELECT ` + batchColumns + ` FROM batches b INNER JOIN candies ca ON ca.id = b.candy_id WHERE b.id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new batch...", infra.Metadata{
+		"batch": batchDTO,
+	})
+
+	batch := domain.Batch{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		inserted := struct{ ID infra.ObjectID }{}
+
+		decoder := tx.Query(ctx, insertQuery, batchDTO.CandyID, batchDTO.Produced, batchDTO.ProductionDate, batchDTO.ExpiryDate)
+		if err := decoder.Decode(ctx, &inserted); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		_, err := s.in.Inventory.WithDb(tx).Move(ctx, domain.StockMovement{
+			CandyID:  batchDTO.CandyID,
+			Kind:     domain.StockProduction,
+			Quantity: batchDTO.Produced,
+			Note:     fmt.Sprintf("Batch %d", inserted.ID),
+			Date:     batchDTO.ProductionDate,
+		})
+
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		if err := tx.Query(ctx, query, inserted.ID).Decode(ctx, &batch); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &batch, nil
+}
+
+// Consume - Takes the order item units from the candy batches still good on the given date, the ones expiring first.
+// Units beyond what the batches have left aren't tracked by batch, the inventory already accounts for them.
+func (s Service) Consume(ctx context.Context, item domain.OrderItem, date string) ([]domain.BatchConsumption, *infra.Error) {
+	const opName infra.OpName = "batches.Consume"
+
+	availableQuery := `
+		SELECT
+			b.id as id,
+			b.remaining as remaining
+		FROM
+			batches b
+		WHERE b.candy_id = $1 AND b.remaining > 0 AND b.expiry_date >= $2::date
+		ORDER BY b.expiry_date, b.id
+		FOR UPDATE
+	`
+
+	consumeQuery := `UPDATE batches SET remaining = remaining - $1 WHERE id = $2`
+	consumptionQuery := `INSERT INTO batch_consumptions (batch_id, order_item_id, quantity) VALUES ($1, $2, $3)`
+
+	consumptions := []domain.BatchConsumption{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		consumptions = []domain.BatchConsumption{}
+
+		cursor, err := tx.QueryAll(ctx, availableQuery, item.CandyID, date)
+		if err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		available := []domain.Batch{}
+
+		for cursor.Next(ctx) {
+			batch := domain.Batch{}
+			if err := cursor.Decode(ctx, &batch); err != nil {
+				cursor.Close(ctx)
+				return errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			available = append(available, batch)
+		}
+
+		cursor.Close(ctx)
+
+		needed := item.Quantity
+
+		for _, batch := range available {
+			if needed == 0 {
+				break
+			}
+
+			quantity := batch.Remaining
+			if needed < quantity {
+				quantity = needed
+			}
+
+			if _, err := tx.Execute(ctx, consumeQuery, quantity, batch.ID); err != nil {
+				return errors.New(ctx, opName, err)
+			}
+
+			if _, err := tx.Execute(ctx, consumptionQuery, batch.ID, item.ID, quantity); err != nil {
+				return errors.New(ctx, opName, err)
+			}
+
+			consumptions = append(consumptions, domain.BatchConsumption{
+				BatchID:     batch.ID,
+				OrderItemID: item.ID,
+				Quantity:    quantity,
+			})
+
+			needed -= quantity
+		}
+
+		if needed > 0 {
+			s.in.Log.WarningMetadata(ctx, opName, "The candy batches didn't have enough units for the order item.", infra.Metadata{
+				"candyId":     item.CandyID,
+				"orderItemId": item.ID,
+				"untracked":   needed,
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return consumptions, nil
+}
+
+// Restore - Puts the units the order took back into their batches. The units of the batches already expired
+// can't be sold again, so they are written off and taken out of the stock instead.
+func (s Service) Restore(ctx context.Context, orderID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "batches.Restore"
+
+	query := `
+		UPDATE batches b SET
+			remaining = CASE WHEN b.expiry_date < CURRENT_DATE THEN b.remaining ELSE b.remaining + c.quantity END,
+			written_off = CASE WHEN b.expiry_date < CURRENT_DATE THEN b.written_off + c.quantity ELSE b.written_off END,
+			written_off_at = CASE WHEN b.expiry_date < CURRENT_DATE THEN CURRENT_DATE ELSE b.written_off_at END
+		FROM (
+			SELECT bc.batch_id, SUM(bc.quantity) as quantity
+			FROM batch_consumptions bc INNER JOIN order_items i ON i.id = bc.order_item_id
+			WHERE i.order_id = $1
+			GROUP BY bc.batch_id
+		) c
+		WHERE b.id = c.batch_id
+		RETURNING b.id as id, b.candy_id as candyId, c.quantity as units, b.expiry_date < CURRENT_DATE as expired
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Restoring the order units to their batches...", infra.Metadata{
+		"orderID": orderID,
+	})
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		cursor, err := tx.QueryAll(ctx, query, orderID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		type restored struct {
+			ID      infra.ObjectID
+			CandyID infra.ObjectID
+			Units   int
+			Expired bool
+		}
+
+		expired := []restored{}
+
+		for cursor.Next(ctx) {
+			row := restored{}
+			if err := cursor.Decode(ctx, &row); err != nil {
+				cursor.Close(ctx)
+				return errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			if row.Expired {
+				expired = append(expired, row)
+			}
+		}
+
+		cursor.Close(ctx)
+
+		for _, batch := range expired {
+			_, err := s.in.Inventory.WithDb(tx).Move(ctx, domain.StockMovement{
+				CandyID:  batch.CandyID,
+				Kind:     domain.StockWaste,
+				Quantity: batch.Units,
+				Note:     fmt.Sprintf("Batch %d expired", batch.ID),
+			})
+
+			if err != nil {
+				return errors.New(ctx, opName, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// WriteOffExpired - Writes off the units left on the batches expired before the given date, taking them out of the stock
+func (s Service) WriteOffExpired(ctx context.Context, date string) ([]domain.Batch, *infra.Error) {
+	const opName infra.OpName = "batches.WriteOffExpired"
+
+	query := `
+		WITH expired AS (
+			SELECT id, remaining
+			FROM batches
+			WHERE remaining > 0 AND expiry_date < $1::date
+			FOR UPDATE
+		)
+		UPDATE batches b SET
+			written_off = b.written_off + e.remaining,
+			remaining = 0,
+			written_off_at = $1::date
+		FROM expired e, candies ca
+		WHERE b.id = e.id AND ca.id = b.candy_id
+		RETURNING ` + batchColumns + `, e.remaining as units
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Writing off the expired batches...", infra.Metadata{
+		"date": date,
+	})
+
+	writtenOff := []domain.Batch{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		writtenOff = []domain.Batch{}
+
+		cursor, err := tx.QueryAll(ctx, query, date)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		units := []int{}
+
+		for cursor.Next(ctx) {
+			row := struct {
+				domain.Batch
+				Units int
+			}{}
+
+			if err := cursor.Decode(ctx, &row); err != nil {
+				cursor.Close(ctx)
+				return errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			writtenOff = append(writtenOff, row.Batch)
+			units = append(units, row.Units)
+		}
+
+		cursor.Close(ctx)
+
+		for i, batch := range writtenOff {
+			_, err := s.in.Inventory.WithDb(tx).Move(ctx, domain.StockMovement{
+				CandyID:  batch.CandyID,
+				Kind:     domain.StockWaste,
+				Quantity: units[i],
+				Note:     fmt.Sprintf("Batch %d expired", batch.ID),
+				Date:     date,
+			})
+
+			if err != nil {
+				return errors.New(ctx, opName, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return writtenOff, nil
+}
+
+// Expiring - Lists the batches with units left that expire within the given days, including the already expired
+func (s Service) Expiring(ctx context.Context, days int) (*domain.ExpiringBatches, *infra.Error) {
+	const opName infra.OpName = "batches.Expiring"
+
+	s.in.Log.Info(ctx, opName, "Listing the expiring batches...")
+
+	query := `
+		SELECT ` + batchColumns + `
+		FROM
+			batches b
+			INNER JOIN candies ca ON ca.id = b.candy_id
+		WHERE b.remaining > 0 AND b.expiry_date <= CURRENT_DATE + $1::integer
+		ORDER BY b.expiry_date, b.id
+	`
+
+	batches, err := s.list(ctx, query, days)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	report := domain.ExpiringBatches{
+		Days:    days,
+		Batches: batches,
+	}
+
+	for _, batch := range batches {
+		report.Units += batch.Remaining
+	}
+
+	return &report, nil
+}
+
+// WrittenOff - Lists the batches written off between from and to (inclusive)
+func (s Service) WrittenOff(ctx context.Context, from string, to string) (*domain.WrittenOffBatches, *infra.Error) {
+	const opName infra.OpName = "batches.WrittenOff"
+
+	s.in.Log.Info(ctx, opName, "Listing the written off batches...")
+
+	query := `
+		SELECT ` + batchColumns + `
+		FROM
+			batches b
+			INNER JOIN candies ca ON ca.id = b.candy_id
+		WHERE b.written_off > 0 AND b.written_off_at BETWEEN $1::date AND $2::date
+		ORDER BY b.written_off_at, b.id
+	`
+
+	batches, err := s.list(ctx, query, from, to)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	report := domain.WrittenOffBatches{
+		From:    from,
+		To:      to,
+		Batches: batches,
+	}
+
+	for _, batch := range batches {
+		report.Units += batch.WrittenOff
+	}
+
+	return &report, nil
+}
+
+func (s Service) list(ctx context.Context, query string, args ...interface{}) ([]domain.Batch, *infra.Error) {
+	const opName infra.OpName = "batches.list"
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	batches := []domain.Batch{}
+
+	for cursor.Next(ctx) {
+		batch := domain.Batch{}
+		if err := cursor.Decode(ctx, &batch); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
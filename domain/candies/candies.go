@@ -4,10 +4,22 @@ import (
 	"context"
 
 	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
 	"github.com/lucasmls/backend-cacautime/infra"
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+var candiesSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":    {Expr: "ca.id", Type: "integer"},
+		"name":  {Expr: "ca.name", Type: "text"},
+		"price": {Expr: "ca.price", Type: "integer"},
+	},
+	DefaultSort:      "name",
+	DefaultDirection: domain.Ascending,
+	ID:               "ca.id",
+}
+
 // ServiceInput ...
 type ServiceInput struct {
 	Db  infra.RelationalDatabaseProvider
@@ -38,52 +50,116 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 	}, nil
 }
 
-// Register ...
+// withDb - Copies the service to query through db, so a candy and its cost history are saved in one transaction
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// WithDb - Copies the candies to query through db, so the recipes set the candy costs in their own transactions
+func (s Service) WithDb(db infra.RelationalDatabaseProvider) domain.CandiesRepository {
+	return s.withDb(db)
+}
+
+// Register - Registers the candy and starts its cost history
 func (s Service) Register(ctx context.Context, candyDto domain.Candy) (*domain.Candy, *infra.Error) {
 	const opName infra.OpName = "candies.Register"
 
-	query := `INSERT INTO candies (name, price) values ($1, $2) RETURNING id, name, price`
+	query := `INSERT INTO candies (name, price, cost) values ($1, $2, $3) RETURNING id, name, price, cost`
 
 	s.in.Log.InfoMetadata(ctx, opName, "Registering a new candy...", infra.Metadata{
 		"candy": candyDto,
 	})
 
-	decoder := s.in.Db.Query(ctx, query, candyDto.Name, candyDto.Price)
 	candy := domain.Candy{}
-	if err := decoder.Decode(ctx, &candy); err != nil {
-		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		decoder := tx.Query(ctx, query, candyDto.Name, candyDto.Price, candyDto.Cost)
+		if err := decoder.Decode(ctx, &candy); err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		return s.withDb(tx).recordCost(ctx, candy)
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
 	}
 
 	return &candy, nil
 }
 
-// List ...
-func (s Service) List(ctx context.Context) ([]domain.Candy, *infra.Error) {
+// List - Lists a page of candies, searching by name
+func (s Service) List(ctx context.Context, opts domain.QueryOptions) (*domain.CandiesPage, *infra.Error) {
 	const opName infra.OpName = "candies.List"
 
-	query := `SELECT id, name, price from candies`
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the candies...", infra.Metadata{
+		"options": opts,
+	})
 
-	s.in.Log.Info(ctx, opName, "Listing all candies...")
+	builder := pagination.Builder{}
+	builder.Search(opts.Search, "ca.name")
 
-	cursor, err := s.in.Db.QueryAll(ctx, query)
-	defer cursor.Close(ctx)
+	page, err := builder.Page(ctx, opts, candiesSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	countQuery := `SELECT COUNT(*) as total FROM candies ca` + builder.Filter()
+
+	query := `
+		SELECT
+			ca.id as id,
+			ca.name as name,
+			ca.price as price,
+			ca.cost as cost,
+			` + page.CursorValue + ` as cursorValue
+		FROM
+			candies ca
+	` + page.Where + page.OrderBy
 
+	count := struct{ Total int }{}
+	if err := s.in.Db.Query(ctx, countQuery, builder.Args()...).Decode(ctx, &count); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
 	if err != nil {
 		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 	}
 
-	var candies []domain.Candy
+	defer cursor.Close(ctx)
+
+	candies := domain.CandiesPage{
+		Data:  []domain.Candy{},
+		Total: count.Total,
+	}
+
+	lastCursorValue := ""
 
 	for cursor.Next(ctx) {
-		candy := domain.Candy{}
-		if err := cursor.Decode(ctx, &candy); err != nil {
+		row := struct {
+			domain.Candy
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
 			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 		}
 
-		candies = append(candies, candy)
+		if len(candies.Data) == page.Limit {
+			last := candies.Data[len(candies.Data)-1]
+			candies.NextCursor = page.NextCursor(lastCursorValue, last.ID)
+			break
+		}
+
+		candies.Data = append(candies.Data, row.Candy)
+		lastCursorValue = row.CursorValue
 	}
 
-	return candies, nil
+	return &candies, nil
 }
 
 // Find ...
@@ -96,7 +172,8 @@ func (s Service) Find(ctx context.Context, candyID infra.ObjectID) (*domain.Cand
 		SELECT
 			ca.id as id,
 			ca.name as name,
-			ca.price as price
+			ca.price as price,
+			ca.cost as cost
 		FROM
 			candies ca
 		WHERE id = $1
@@ -114,32 +191,129 @@ func (s Service) Find(ctx context.Context, candyID infra.ObjectID) (*domain.Cand
 	return &candy, nil
 }
 
-// Update ...
+// Update - Updates the candy, adding the new cost to its history when it changes
 func (s Service) Update(ctx context.Context, candyID infra.ObjectID, candyDTO domain.Candy) (*domain.Candy, *infra.Error) {
 	const opName infra.OpName = "candies.Update"
 
-	query := `UPDATE candies SET name = $1, price = $2 WHERE id = $3 RETURNING id, name, price`
+	query := `UPDATE candies SET name = $1, price = $2, cost = $3 WHERE id = $4 RETURNING id, name, price, cost`
 
 	s.in.Log.InfoMetadata(ctx, opName, "Updating a candy...", infra.Metadata{
 		"candyID": candyID,
 		"dto":     candyDTO,
 	})
 
-	_, err := s.Find(ctx, candyID)
+	candy := domain.Candy{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		current, err := txService.Find(ctx, candyID)
+		if err != nil {
+			return err
+		}
+
+		decoder := tx.Query(ctx, query, candyDTO.Name, candyDTO.Price, candyDTO.Cost, candyID)
+		if err := decoder.Decode(ctx, &candy); err != nil {
+			return errors.New(ctx, opName, err, infra.KindBadRequest)
+		}
+
+		if current.Cost == candy.Cost {
+			return nil
+		}
+
+		return txService.recordCost(ctx, candy)
+	})
+
 	if err != nil {
 		return nil, errors.New(ctx, opName, err)
 	}
 
-	decoder := s.in.Db.Query(ctx, query, candyDTO.Name, candyDTO.Price, candyID)
+	return &candy, nil
+}
+
+// SetCost - Sets the candy unit cost, adding it to its history when it changes
+func (s Service) SetCost(ctx context.Context, candyID infra.ObjectID, cost int) (*domain.Candy, *infra.Error) {
+	const opName infra.OpName = "candies.SetCost"
+
+	query := `UPDATE candies SET cost = $1 WHERE id = $2 RETURNING id, name, price, cost`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Setting the candy cost...", infra.Metadata{
+		"candyID": candyID,
+		"cost":    cost,
+	})
 
 	candy := domain.Candy{}
-	if err := decoder.Decode(ctx, &candy); err != nil {
-		return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		current, err := txService.Find(ctx, candyID)
+		if err != nil {
+			return err
+		}
+
+		if current.Cost == cost {
+			candy = *current
+			return nil
+		}
+
+		if err := tx.Query(ctx, query, cost, candyID).Decode(ctx, &candy); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		return txService.recordCost(ctx, candy)
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
 	}
 
 	return &candy, nil
 }
 
+// Costs - Lists the unit costs the candy had, the current one first
+func (s Service) Costs(ctx context.Context, candyID infra.ObjectID) ([]domain.CandyCost, *infra.Error) {
+	const opName infra.OpName = "candies.Costs"
+
+	s.in.Log.Info(ctx, opName, "Listing the candy costs...")
+
+	query := `
+		SELECT
+			cc.id as id,
+			cc.candy_id as candyId,
+			cc.cost as cost,
+			cc.effective_from::text as effectiveFrom
+		FROM
+			candy_costs cc
+		WHERE cc.candy_id = $1
+		ORDER BY cc.effective_from DESC, cc.id DESC
+	`
+
+	if _, err := s.Find(ctx, candyID); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, candyID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	costs := []domain.CandyCost{}
+
+	for cursor.Next(ctx) {
+		cost := domain.CandyCost{}
+		if err := cursor.Decode(ctx, &cost); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		costs = append(costs, cost)
+	}
+
+	return costs, nil
+}
+
 // Delete ..
 func (s Service) Delete(ctx context.Context, candyID infra.ObjectID) *infra.Error {
 	const opName infra.OpName = "candies.Delete"
@@ -166,3 +340,18 @@ func (s Service) Delete(ctx context.Context, candyID infra.ObjectID) *infra.Erro
 
 	return nil
 }
+
+// recordCost - Adds the candy current cost to its history, effective from today
+func (s Service) recordCost(ctx context.Context, candy domain.Candy) *infra.Error {
+	const opName infra.OpName = "candies.recordCost"
+
+	query := `INSERT INTO candy_costs (candy_id, cost) VALUES ($1, $2)`
+
+	if _, err := s.in.Db.Execute(ctx, query, candy.ID, candy.Cost); err != nil {
+		return errors.New(ctx, opName, err, infra.Metadata{
+			"candyId": candy.ID,
+		})
+	}
+
+	return nil
+}
@@ -8,12 +8,22 @@ import (
 
 // UsersRepository ...
 type UsersRepository interface {
+	Register(context.Context, User) (*User, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*User, *infra.Error)
 	FindByEmail(context.Context, string) (*User, *infra.Error)
+	List(context.Context) ([]User, *infra.Error)
+	Update(context.Context, infra.ObjectID, User) (*User, *infra.Error)
+	Deactivate(context.Context, infra.ObjectID) *infra.Error
 }
 
 // AuthRepository ...
 type AuthRepository interface {
-	Login(context.Context, string, string) (string, *infra.Error)
+	Login(context.Context, string, string, string) (*Tokens, *infra.Error)
+	Refresh(context.Context, string) (*Tokens, *infra.Error)
+	Logout(context.Context, infra.ObjectID) *infra.Error
+	LogoutAll(context.Context, infra.ObjectID) *infra.Error
+	SessionActive(context.Context, infra.ObjectID) (bool, *infra.Error)
+	LoginAttempts(context.Context, LoginAttemptsFilter, QueryOptions) (*LoginAttemptsPage, *infra.Error)
 }
 
 // CustomersRepository ...
@@ -21,22 +31,109 @@ type CustomersRepository interface {
 	Register(context.Context, Customer) (*Customer, *infra.Error)
 	Update(context.Context, infra.ObjectID, Customer) (*Customer, *infra.Error)
 	Delete(context.Context, infra.ObjectID) *infra.Error
-	List(context.Context) ([]Customer, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Customer, *infra.Error)
+	List(context.Context, QueryOptions) (*CustomersPage, *infra.Error)
+	Statement(context.Context, infra.ObjectID, string, string) (*CustomerStatement, *infra.Error)
 }
 
 // CandiesRepository ...
 type CandiesRepository interface {
 	Register(context.Context, Candy) (*Candy, *infra.Error)
-	List(context.Context) ([]Candy, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Candy, *infra.Error)
+	List(context.Context, QueryOptions) (*CandiesPage, *infra.Error)
+	Costs(context.Context, infra.ObjectID) ([]CandyCost, *infra.Error)
 	Update(context.Context, infra.ObjectID, Candy) (*Candy, *infra.Error)
+	SetCost(context.Context, infra.ObjectID, int) (*Candy, *infra.Error)
 	Delete(context.Context, infra.ObjectID) *infra.Error
+	WithDb(infra.RelationalDatabaseProvider) CandiesRepository
+}
+
+// IngredientsRepository ...
+type IngredientsRepository interface {
+	Register(context.Context, Ingredient) (*Ingredient, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Ingredient, *infra.Error)
+	List(context.Context) ([]Ingredient, *infra.Error)
+	Update(context.Context, infra.ObjectID, Ingredient) (*Ingredient, *infra.Error)
+	Delete(context.Context, infra.ObjectID) *infra.Error
+}
+
+// RecipesRepository ...
+type RecipesRepository interface {
+	Save(context.Context, Recipe) (*Recipe, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Recipe, *infra.Error)
+	Delete(context.Context, infra.ObjectID) *infra.Error
+	Recost(context.Context, infra.ObjectID) ([]Candy, *infra.Error)
+	ShoppingList(context.Context, []PlannedBatch) (*ShoppingList, *infra.Error)
+	WithDb(infra.RelationalDatabaseProvider) RecipesRepository
+}
+
+// OrdersRepository ...
+type OrdersRepository interface {
+	Register(context.Context, Order) (*Order, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Order, *infra.Error)
+	Update(context.Context, infra.ObjectID, Order) (*Order, *infra.Error)
+	Delete(context.Context, infra.ObjectID) *infra.Error
+	WithDb(infra.RelationalDatabaseProvider) OrdersRepository
+}
+
+// PaymentsRepository ...
+type PaymentsRepository interface {
+	Register(context.Context, Payment) (*Payment, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Payment, *infra.Error)
+	Delete(context.Context, infra.ObjectID) *infra.Error
+	Balance(context.Context, infra.ObjectID) (*CustomerBalance, *infra.Error)
+}
+
+// InventoryRepository ...
+type InventoryRepository interface {
+	Move(context.Context, StockMovement) (*StockLevel, *infra.Error)
+	Movements(context.Context, infra.ObjectID, QueryOptions) (*StockMovementsPage, *infra.Error)
+	Levels(context.Context) (*Inventory, *infra.Error)
+	SetLowStockThreshold(context.Context, infra.ObjectID, int) (*StockLevel, *infra.Error)
+	WithDb(infra.RelationalDatabaseProvider) InventoryRepository
+}
+
+// BatchesRepository ...
+type BatchesRepository interface {
+	Register(context.Context, Batch) (*Batch, *infra.Error)
+	Consume(context.Context, OrderItem, string) ([]BatchConsumption, *infra.Error)
+	Restore(context.Context, infra.ObjectID) *infra.Error
+	WriteOffExpired(context.Context, string) ([]Batch, *infra.Error)
+	Expiring(context.Context, int) (*ExpiringBatches, *infra.Error)
+	WrittenOff(context.Context, string, string) (*WrittenOffBatches, *infra.Error)
+	WithDb(infra.RelationalDatabaseProvider) BatchesRepository
+}
+
+// DutiesRepository ...
+type DutiesRepository interface {
+	Open(context.Context, Duty) (*Duty, *infra.Error)
+	Close(context.Context, infra.ObjectID, Duty) (*Duty, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Duty, *infra.Error)
+	Summary(context.Context, infra.ObjectID) (*DutySummary, *infra.Error)
+}
+
+// ExpensesRepository ...
+type ExpensesRepository interface {
+	Register(context.Context, Expense) (*Expense, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Expense, *infra.Error)
+	List(context.Context, ExpensesFilter, QueryOptions) (*ExpensesPage, *infra.Error)
+	Update(context.Context, infra.ObjectID, Expense) (*Expense, *infra.Error)
+	Delete(context.Context, infra.ObjectID) *infra.Error
+	ProfitAndLoss(context.Context, int, int) (*ProfitAndLoss, *infra.Error)
 }
 
 // SalesRepository ...
 type SalesRepository interface {
 	Register(context.Context, Sale) (*Sale, *infra.Error)
+	Find(context.Context, infra.ObjectID) (*Sale, *infra.Error)
 	Update(context.Context, infra.ObjectID, Sale) (*Sale, *infra.Error)
 	Delete(context.Context, infra.ObjectID) *infra.Error
+	List(context.Context, SalesFilter, QueryOptions) (*SalesPage, *infra.Error)
 	Months(context.Context) ([]Month, *infra.Error)
 	MonthSales(context.Context, int, int) (*MonthSales, *infra.Error)
+	Summary(context.Context, string, string) (*SalesSummary, *infra.Error)
+	Debtors(context.Context, DebtorsSort) (*DebtorsReport, *infra.Error)
+	Profit(context.Context, string, string) (*ProfitReport, *infra.Error)
+	CandiesRanking(context.Context, CandiesRankingSort, RankingOptions) (*CandiesRanking, *infra.Error)
+	CustomersRanking(context.Context, CustomersRankingSort, RankingOptions) (*CustomersRanking, *infra.Error)
 }
@@ -4,10 +4,22 @@ import (
 	"context"
 
 	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
 	"github.com/lucasmls/backend-cacautime/infra"
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+var customersSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":    {Expr: "cu.id", Type: "integer"},
+		"name":  {Expr: "cu.name", Type: "text"},
+		"phone": {Expr: "COALESCE(cu.phone, '')", Type: "text"},
+	},
+	DefaultSort:      "name",
+	DefaultDirection: domain.Ascending,
+	ID:               "cu.id",
+}
+
 // ServiceInput ...
 type ServiceInput struct {
 	Db  infra.RelationalDatabaseProvider
@@ -38,6 +50,14 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 	}, nil
 }
 
+// withDb - Copies the service to query through db, so the customer is found in the transaction that updates it
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
 // Register ...
 func (s Service) Register(ctx context.Context, customerDto domain.Customer) (*domain.Customer, *infra.Error) {
 	const opName infra.OpName = "customers.Register"
@@ -58,33 +78,74 @@ func (s Service) Register(ctx context.Context, customerDto domain.Customer) (*do
 	return &customer, nil
 }
 
-// List ...
-func (s Service) List(ctx context.Context) ([]domain.Customer, *infra.Error) {
+// List - Lists a page of customers, searching by name or phone
+func (s Service) List(ctx context.Context, opts domain.QueryOptions) (*domain.CustomersPage, *infra.Error) {
 	const opName infra.OpName = "customers.List"
 
-	query := `SELECT id, name, phone from customers`
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the customers...", infra.Metadata{
+		"options": opts,
+	})
+
+	builder := pagination.Builder{}
+	builder.Search(opts.Search, "cu.name", "cu.phone")
+
+	page, err := builder.Page(ctx, opts, customersSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
 
-	s.in.Log.Info(ctx, opName, "Listing all customers...")
+	countQuery := `SELECT COUNT(*) as total FROM customers cu` + builder.Filter()
+
+	query := `
+		SELECT
+			cu.id as id,
+			cu.name as name,
+			COALESCE(cu.phone, '') as phone,
+			` + page.CursorValue + ` as cursorValue
+		FROM
+			customers cu
+	` + page.Where + page.OrderBy
+
+	count := struct{ Total int }{}
+	if err := s.in.Db.Query(ctx, countQuery, builder.Args()...).Decode(ctx, &count); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
 
-	cursor, err := s.in.Db.QueryAll(ctx, query)
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
 	if err != nil {
 		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 	}
 
 	defer cursor.Close(ctx)
 
-	var customers []domain.Customer
+	customers := domain.CustomersPage{
+		Data:  []domain.Customer{},
+		Total: count.Total,
+	}
+
+	lastCursorValue := ""
 
 	for cursor.Next(ctx) {
-		customer := domain.Customer{}
-		if err := cursor.Decode(ctx, &customer); err != nil {
+		row := struct {
+			domain.Customer
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
 			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
 		}
 
-		customers = append(customers, customer)
+		if len(customers.Data) == page.Limit {
+			last := customers.Data[len(customers.Data)-1]
+			customers.NextCursor = page.NextCursor(lastCursorValue, last.ID)
+			break
+		}
+
+		customers.Data = append(customers.Data, row.Customer)
+		lastCursorValue = row.CursorValue
 	}
 
-	return customers, nil
+	return &customers, nil
 }
 
 // Find ...
@@ -97,7 +158,7 @@ func (s Service) Find(ctx context.Context, customerID infra.ObjectID) (*domain.C
 		SELECT
 			cu.id as id,
 			cu.name as name,
-			cu.phone as phone
+			COALESCE(cu.phone, '') as phone
 		FROM
 			customers cu
 		WHERE id = $1
@@ -126,16 +187,23 @@ func (s Service) Update(ctx context.Context, customerID infra.ObjectID, customer
 		"dto":        customerDto,
 	})
 
-	_, err := s.Find(ctx, customerID)
-	if err != nil {
-		return nil, errors.New(ctx, opName, err)
-	}
+	customer := domain.Customer{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := s.withDb(tx).Find(ctx, customerID); err != nil {
+			return err
+		}
 
-	decoder := s.in.Db.Query(ctx, query, customerDto.Name, customerDto.Phone, customerID)
+		decoder := tx.Query(ctx, query, customerDto.Name, customerDto.Phone, customerID)
+		if err := decoder.Decode(ctx, &customer); err != nil {
+			return errors.New(ctx, opName, err, infra.KindBadRequest)
+		}
 
-	customer := domain.Customer{}
-	if err := decoder.Decode(ctx, &customer); err != nil {
-		return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
 	}
 
 	return &customer, nil
@@ -167,3 +235,125 @@ func (s Service) Delete(ctx context.Context, customerID infra.ObjectID) *infra.E
 
 	return nil
 }
+
+// statementEntries lists every order, settlement on registration and payment of the customer ($1)
+const statementEntries = `
+	WITH entries AS (
+		SELECT
+			b.date as date,
+			'sale' as kind,
+			1 as position,
+			b.order_id as referenceId,
+			b.payment_method as paymentMethod,
+			b.total as debit,
+			0 as credit
+		FROM order_balances b
+		WHERE b.customer_id = $1
+
+		UNION ALL
+
+		SELECT
+			b.date,
+			'settlement',
+			2,
+			b.order_id,
+			b.payment_method,
+			0,
+			b.total - b.allocated
+		FROM order_balances b
+		WHERE b.customer_id = $1 AND b.status = 'paid' AND b.total > b.allocated
+
+		UNION ALL
+
+		SELECT
+			p.date,
+			'payment',
+			3,
+			p.id,
+			p.payment_method,
+			0,
+			p.amount
+		FROM payments p
+		WHERE p.customer_id = $1
+	)
+`
+
+// Statement - Lists the customer sales and payments between from and to (inclusive) with the running balance
+func (s Service) Statement(ctx context.Context, customerID infra.ObjectID, from string, to string) (*domain.CustomerStatement, *infra.Error) {
+	const opName infra.OpName = "customers.Statement"
+
+	openingQuery := statementEntries + `
+		SELECT COALESCE(SUM(debit - credit), 0) as openingBalance
+		FROM entries
+		WHERE date < $2::date
+	`
+
+	entriesQuery := statementEntries + `
+		SELECT
+			date::text as date,
+			kind,
+			referenceId,
+			paymentMethod,
+			debit,
+			credit
+		FROM entries
+		WHERE date BETWEEN $2::date AND $3::date
+		ORDER BY entries.date, position, referenceId
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Building the customer statement...", infra.Metadata{
+		"customerID": customerID,
+		"from":       from,
+		"to":         to,
+	})
+
+	customer, err := s.Find(ctx, customerID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	statement := domain.CustomerStatement{
+		CustomerID:      customer.ID,
+		CustomerName:    customer.Name,
+		From:            from,
+		To:              to,
+		ByPaymentMethod: map[domain.PaymentMethod]int{},
+		Entries:         []domain.StatementEntry{},
+	}
+
+	if err := s.in.Db.Query(ctx, openingQuery, customerID, from).Decode(ctx, &statement); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, entriesQuery, customerID, from, to)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+	}
+
+	defer cursor.Close(ctx)
+
+	balance := statement.OpeningBalance
+
+	for cursor.Next(ctx) {
+		entry := domain.StatementEntry{}
+		if err := cursor.Decode(ctx, &entry); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		balance += entry.Debit - entry.Credit
+		entry.Balance = balance
+
+		statement.Debits += entry.Debit
+		statement.Credits += entry.Credit
+
+		if entry.Credit > 0 {
+			statement.ByPaymentMethod[entry.PaymentMethod] += entry.Credit
+		}
+
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	statement.ClosingBalance = balance
+
+	return &statement, nil
+}
@@ -0,0 +1,283 @@
+package duties
+
+import (
+	"context"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// dutyColumns are the columns decoded into domain.Duty, from duties (d)
+const dutyColumns = `
+	d.id as id,
+	d.closed_at IS NULL as open,
+	to_char(d.opened_at, 'YYYY-MM-DD"T"HH24:MI:SS') as openedAt,
+	COALESCE(to_char(d.closed_at, 'YYYY-MM-DD"T"HH24:MI:SS'), '') as closedAt,
+	d.opening_cash as openingCash,
+	COALESCE(d.counted_cash, 0) as countedCash,
+	COALESCE(d.counted_transfer, 0) as countedTransfer,
+	COALESCE(d.note, '') as note
+`
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db  infra.RelationalDatabaseProvider
+	Log infra.LogProvider
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "duties.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// withDb - Copies the service to query through db, so a duty is read and changed in one transaction
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Open - Opens a duty with the cash in the box, it fails with a conflict when another duty is still open
+func (s Service) Open(ctx context.Context, dutyDTO domain.Duty) (*domain.Duty, *infra.Error) {
+	const opName infra.OpName = "duties.Open"
+
+	query := `
+		INSERT INTO duties (opening_cash, note) VALUES ($1, NULLIF($2, ''))
+		RETURNING id
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Opening a duty...", infra.Metadata{
+		"duty": dutyDTO,
+	})
+
+	var duty *domain.Duty
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		inserted := struct{ ID infra.ObjectID }{}
+		if err := tx.Query(ctx, query, dutyDTO.OpeningCash, dutyDTO.Note).Decode(ctx, &inserted); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		opened, err := s.withDb(tx).Find(ctx, inserted.ID)
+		if err != nil {
+			return err
+		}
+
+		duty = opened
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return duty, nil
+}
+
+// Close - Closes the duty with the cash and transfers counted
+func (s Service) Close(ctx context.Context, dutyID infra.ObjectID, dutyDTO domain.Duty) (*domain.Duty, *infra.Error) {
+	const opName infra.OpName = "duties.Close"
+
+	query := `
+		UPDATE duties SET
+			closed_at = now(),
+			counted_cash = $1,
+			counted_transfer = $2,
+			note = COALESCE(NULLIF($3, ''), note)
+		WHERE id = $4 AND closed_at IS NULL
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Closing a duty...", infra.Metadata{
+		"dutyID": dutyID,
+		"dto":    dutyDTO,
+	})
+
+	var duty *domain.Duty
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		current, err := txService.Find(ctx, dutyID)
+		if err != nil {
+			return err
+		}
+
+		if !current.Open {
+			return errors.New(ctx, opName, "The duty is already closed.", infra.KindConflict)
+		}
+
+		if _, err := tx.Execute(ctx, query, dutyDTO.CountedCash, dutyDTO.CountedTransfer, dutyDTO.Note, dutyID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		closed, err := txService.Find(ctx, dutyID)
+		if err != nil {
+			return err
+		}
+
+		duty = closed
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return duty, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, dutyID infra.ObjectID) (*domain.Duty, *infra.Error) {
+	const opName infra.OpName = "duties.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the duty...")
+
+	query := `SELECT ` + dutyColumns + ` FROM duties d WHERE d.id = $1`
+
+	duty := domain.Duty{}
+	if err := s.in.Db.Query(ctx, query, dutyID).Decode(ctx, &duty); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &duty, nil
+}
+
+// Summary - Sums the duty orders and payments by payment method and compares them with what was counted.
+// While the duty is open nothing was counted yet, so the differences are the whole expected amounts.
+func (s Service) Summary(ctx context.Context, dutyID infra.ObjectID) (*domain.DutySummary, *infra.Error) {
+	const opName infra.OpName = "duties.Summary"
+
+	s.in.Log.Info(ctx, opName, "Summarizing the duty...")
+
+	// Orders registered as paid were collected on the spot, the others are collected by later payments
+	ordersQuery := `
+		SELECT
+			b.payment_method as paymentMethod,
+			COUNT(*) as orders,
+			SUM(b.total) as total,
+			SUM(CASE WHEN b.status = 'paid' THEN b.total - b.allocated ELSE 0 END) as collected
+		FROM
+			order_balances b
+			INNER JOIN orders o ON o.id = b.order_id
+		WHERE o.duty_id = $1
+		GROUP BY b.payment_method
+	`
+
+	paymentsQuery := `
+		SELECT
+			p.payment_method as paymentMethod,
+			SUM(p.amount) as amount
+		FROM
+			payments p
+		WHERE p.duty_id = $1
+		GROUP BY p.payment_method
+	`
+
+	duty, err := s.Find(ctx, dutyID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	summary := domain.DutySummary{
+		Duty: *duty,
+	}
+
+	money := domain.DutyReconciliation{
+		PaymentMethod: domain.Money,
+		Opening:       duty.OpeningCash,
+		Counted:       duty.CountedCash,
+	}
+
+	transfer := domain.DutyReconciliation{
+		PaymentMethod: domain.Transfer,
+		Counted:       duty.CountedTransfer,
+	}
+
+	reconciliations := map[domain.PaymentMethod]*domain.DutyReconciliation{
+		domain.Money:    &money,
+		domain.Transfer: &transfer,
+	}
+
+	ordersCursor, err := s.in.Db.QueryAll(ctx, ordersQuery, dutyID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer ordersCursor.Close(ctx)
+
+	for ordersCursor.Next(ctx) {
+		row := struct {
+			PaymentMethod domain.PaymentMethod
+			Orders        int
+			Total         int
+			Collected     int
+		}{}
+
+		if err := ordersCursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		summary.Orders += row.Orders
+		summary.Total += row.Total
+		summary.Scheduled += row.Total - row.Collected
+
+		if reconciliation, ok := reconciliations[row.PaymentMethod]; ok {
+			reconciliation.Sales += row.Collected
+		}
+	}
+
+	paymentsCursor, err := s.in.Db.QueryAll(ctx, paymentsQuery, dutyID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer paymentsCursor.Close(ctx)
+
+	for paymentsCursor.Next(ctx) {
+		row := struct {
+			PaymentMethod domain.PaymentMethod
+			Amount        int
+		}{}
+
+		if err := paymentsCursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if reconciliation, ok := reconciliations[row.PaymentMethod]; ok {
+			reconciliation.Payments += row.Amount
+		}
+	}
+
+	for _, reconciliation := range []*domain.DutyReconciliation{&money, &transfer} {
+		reconciliation.Expected = reconciliation.Opening + reconciliation.Sales + reconciliation.Payments
+		reconciliation.Difference = reconciliation.Counted - reconciliation.Expected
+
+		summary.Difference += reconciliation.Difference
+		summary.Reconciliation = append(summary.Reconciliation, *reconciliation)
+	}
+
+	return &summary, nil
+}
@@ -4,12 +4,37 @@ import (
 	"github.com/lucasmls/backend-cacautime/infra"
 )
 
-// User ...
+// User - Password is the bcrypt hash, it never leaves the API
 type User struct {
 	ID       infra.ObjectID `json:"id"`
 	Name     string         `json:"name"`
 	Email    string         `json:"email"`
-	Password string         `json:"password"`
+	Password string         `json:"-"`
+	Role     Role           `json:"role"`
+	Active   bool           `json:"active"`
+}
+
+// Tokens - The access token authorizes the requests until it expires, then the refresh token gets new ones
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// LoginAttempt - UserID is 0 when the e-mail doesn't belong to any user
+type LoginAttempt struct {
+	ID        infra.ObjectID `json:"id"`
+	Email     string         `json:"email"`
+	IP        string         `json:"ip"`
+	UserID    infra.ObjectID `json:"userId"`
+	Result    LoginResult    `json:"result"`
+	CreatedAt string         `json:"createdAt"`
+}
+
+// LoginAttemptsPage ...
+type LoginAttemptsPage struct {
+	Data       []LoginAttempt `json:"data"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor"`
 }
 
 // Customer ...
@@ -19,11 +44,28 @@ type Customer struct {
 	Phone string         `json:"phone"`
 }
 
+// CustomerDetails - A customer with how much it owes and its last purchase, if any
+type CustomerDetails struct {
+	Customer
+
+	Balance      CustomerBalance `json:"balance"`
+	LastPurchase *MonthSale      `json:"lastPurchase"`
+}
+
 // Candy ...
 type Candy struct {
 	ID    infra.ObjectID `json:"id"`
 	Name  string         `json:"name"`
 	Price int            `json:"price"`
+	Cost  int            `json:"cost"`
+}
+
+// CandyCost - A unit cost the candy had, from the given date until the next one
+type CandyCost struct {
+	ID            infra.ObjectID `json:"id"`
+	CandyID       infra.ObjectID `json:"candyId"`
+	Cost          int            `json:"cost"`
+	EffectiveFrom string         `json:"effectiveFrom"`
 }
 
 // Sale ...
@@ -31,9 +73,93 @@ type Sale struct {
 	ID            infra.ObjectID `json:"id"`
 	CustomerID    infra.ObjectID `json:"customerId"`
 	CandyID       infra.ObjectID `json:"candyId"`
+	CandyName     string         `json:"candyName"`
+	CandyPrice    int            `json:"candyPrice"`
 	Status        Status         `json:"status"`
 	PaymentMethod PaymentMethod  `json:"paymentMethod"`
 	Date          string         `json:"date"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SaleDetails - A sale with its customer and the current state of its candy
+type SaleDetails struct {
+	Sale
+
+	Customer Customer `json:"customer"`
+	Candy    Candy    `json:"candy"`
+}
+
+// Order ...
+type Order struct {
+	ID            infra.ObjectID `json:"id"`
+	CustomerID    infra.ObjectID `json:"customerId"`
+	Status        Status         `json:"status"`
+	PaymentMethod PaymentMethod  `json:"paymentMethod"`
+	Date          string         `json:"date"`
+	DutyID        infra.ObjectID `json:"dutyId,omitempty"`
+	Subtotal      int            `json:"subtotal"`
+	Discount      int            `json:"discount"`
+	Total         int            `json:"total"`
+
+	Items    []OrderItem `json:"items"`
+	Warnings []string    `json:"warnings,omitempty"`
+}
+
+// OrderItem ...
+type OrderItem struct {
+	ID        infra.ObjectID `json:"id"`
+	OrderID   infra.ObjectID `json:"orderId"`
+	CandyID   infra.ObjectID `json:"candyId"`
+	CandyName string         `json:"candyName"`
+	UnitPrice int            `json:"unitPrice"`
+	UnitCost  int            `json:"unitCost"`
+	Quantity  int            `json:"quantity"`
+	Discount  int            `json:"discount"`
+	Total     int            `json:"total"`
+}
+
+// Payment ...
+type Payment struct {
+	ID            infra.ObjectID `json:"id"`
+	CustomerID    infra.ObjectID `json:"customerId"`
+	Amount        int            `json:"amount"`
+	PaymentMethod PaymentMethod  `json:"paymentMethod"`
+	Date          string         `json:"date"`
+	Note          string         `json:"note"`
+	DutyID        infra.ObjectID `json:"dutyId,omitempty"`
+	Unallocated   int            `json:"unallocated"`
+
+	Allocations []PaymentAllocation `json:"allocations"`
+}
+
+// PaymentAllocation - The part of a payment that settled an order
+type PaymentAllocation struct {
+	ID        infra.ObjectID `json:"id"`
+	PaymentID infra.ObjectID `json:"paymentId"`
+	OrderID   infra.ObjectID `json:"orderId"`
+	Amount    int            `json:"amount"`
+}
+
+// OrderBalance ...
+type OrderBalance struct {
+	OrderID     infra.ObjectID `json:"orderId"`
+	Date        string         `json:"date"`
+	Status      Status         `json:"status"`
+	Total       int            `json:"total"`
+	Allocated   int            `json:"allocated"`
+	Outstanding int            `json:"outstanding"`
+}
+
+// CustomerBalance ...
+type CustomerBalance struct {
+	CustomerID  infra.ObjectID `json:"customerId"`
+	Purchased   int            `json:"purchased"`
+	Outstanding int            `json:"outstanding"`
+	Credit      int            `json:"credit"`
+	Balance     int            `json:"balance"`
+
+	OpenOrders []OrderBalance `json:"openOrders"`
 }
 
 // Month ...
@@ -46,6 +172,7 @@ type Month struct {
 // MonthSale ...
 type MonthSale struct {
 	ID            infra.ObjectID `json:"id"`
+	ItemID        infra.ObjectID `json:"itemId"`
 	Status        Status         `json:"status"`
 	PaymentMethod PaymentMethod  `json:"paymentMethod"`
 	Date          string         `json:"date"`
@@ -53,6 +180,12 @@ type MonthSale struct {
 	CandyID    infra.ObjectID `json:"candyId"`
 	CandyName  string         `json:"candyName"`
 	CandyPrice int            `json:"candyPrice"`
+	CandyCost  int            `json:"candyCost"`
+	Quantity   int            `json:"quantity"`
+	Discount   int            `json:"discount"`
+	Amount     int            `json:"amount"`
+	Cost       int            `json:"cost"`
+	Profit     int            `json:"profit"`
 
 	CustomerID   infra.ObjectID `json:"customerId"`
 	CustomerName string         `json:"customerName"`
@@ -63,6 +196,432 @@ type MonthSales struct {
 	Subtotal        int `json:"subtotal"`
 	PaidAmount      int `json:"paidAmount"`
 	ScheduledAmount int `json:"scheduledAmount"`
+	Cost            int `json:"cost"`
+	GrossMargin     int `json:"grossMargin"`
 
 	Sales []MonthSale `json:"sales"`
 }
+
+// SalesTotals - The sales of a period added up, each order counting as one sale
+type SalesTotals struct {
+	Subtotal        int `json:"subtotal"`
+	PaidAmount      int `json:"paidAmount"`
+	ScheduledAmount int `json:"scheduledAmount"`
+	Sales           int `json:"sales"`
+	Customers       int `json:"customers"`
+	AverageTicket   int `json:"averageTicket"`
+}
+
+// MonthSummary ...
+type MonthSummary struct {
+	Month int `json:"month"`
+	Year  int `json:"year"`
+
+	SalesTotals
+}
+
+// YearSummary - The totals of the year months within the summary, customers are counted once per year
+type YearSummary struct {
+	Year int `json:"year"`
+
+	SalesTotals
+}
+
+// SalesSummary - Every month between from and to (YYYY-MM, inclusive), including the ones without sales
+type SalesSummary struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Months []MonthSummary `json:"months"`
+	Years  []YearSummary  `json:"years"`
+}
+
+// CustomersPage ...
+type CustomersPage struct {
+	Data       []Customer `json:"data"`
+	Total      int        `json:"total"`
+	NextCursor string     `json:"nextCursor"`
+}
+
+// CandiesPage ...
+type CandiesPage struct {
+	Data       []Candy `json:"data"`
+	Total      int     `json:"total"`
+	NextCursor string  `json:"nextCursor"`
+}
+
+// SalesPage - A page of sales, one per order item. The amounts add up every sale matching the filter, not only the page.
+type SalesPage struct {
+	Data       []MonthSale `json:"data"`
+	Total      int         `json:"total"`
+	NextCursor string      `json:"nextCursor"`
+
+	Subtotal        int `json:"subtotal"`
+	PaidAmount      int `json:"paidAmount"`
+	ScheduledAmount int `json:"scheduledAmount"`
+}
+
+// AgingBuckets - Outstanding amounts grouped by how many days ago the orders were made
+type AgingBuckets struct {
+	UpTo30Days     int `json:"upTo30Days"`
+	From31To60Days int `json:"from31To60Days"`
+	From61To90Days int `json:"from61To90Days"`
+	Over90Days     int `json:"over90Days"`
+}
+
+// Debtor ...
+type Debtor struct {
+	CustomerID       infra.ObjectID `json:"customerId"`
+	CustomerName     string         `json:"customerName"`
+	CustomerPhone    string         `json:"customerPhone"`
+	Outstanding      int            `json:"outstanding"`
+	UnpaidOrders     int            `json:"unpaidOrders"`
+	OldestUnpaidDate string         `json:"oldestUnpaidDate"`
+	OldestUnpaidDays int            `json:"oldestUnpaidDays"`
+
+	AgingBuckets
+}
+
+// DebtorsReport ...
+type DebtorsReport struct {
+	Outstanding int `json:"outstanding"`
+
+	AgingBuckets
+
+	Debtors []Debtor `json:"debtors"`
+}
+
+// StatementEntry ...
+type StatementEntry struct {
+	Date          string             `json:"date"`
+	Kind          StatementEntryKind `json:"kind"`
+	ReferenceID   infra.ObjectID     `json:"referenceId"`
+	PaymentMethod PaymentMethod      `json:"paymentMethod"`
+	Debit         int                `json:"debit"`
+	Credit        int                `json:"credit"`
+	Balance       int                `json:"balance"`
+}
+
+// CustomerStatement ...
+type CustomerStatement struct {
+	CustomerID      infra.ObjectID        `json:"customerId"`
+	CustomerName    string                `json:"customerName"`
+	From            string                `json:"from"`
+	To              string                `json:"to"`
+	OpeningBalance  int                   `json:"openingBalance"`
+	Debits          int                   `json:"debits"`
+	Credits         int                   `json:"credits"`
+	ClosingBalance  int                   `json:"closingBalance"`
+	ByPaymentMethod map[PaymentMethod]int `json:"byPaymentMethod"`
+
+	Entries []StatementEntry `json:"entries"`
+}
+
+// StockLevel - How many units of a candy are in stock
+type StockLevel struct {
+	CandyID           infra.ObjectID `json:"candyId"`
+	CandyName         string         `json:"candyName"`
+	Stock             int            `json:"stock"`
+	LowStockThreshold int            `json:"lowStockThreshold"`
+	LowStock          bool           `json:"lowStock"`
+}
+
+// Inventory ...
+type Inventory struct {
+	LowStockCount int `json:"lowStockCount"`
+
+	Levels []StockLevel `json:"levels"`
+}
+
+// StockMovement - A change on a candy stock, Quantity is positive when it adds to the stock and negative otherwise
+type StockMovement struct {
+	ID       infra.ObjectID    `json:"id"`
+	CandyID  infra.ObjectID    `json:"candyId"`
+	Kind     StockMovementKind `json:"kind"`
+	Quantity int               `json:"quantity"`
+	OrderID  infra.ObjectID    `json:"orderId,omitempty"`
+	Note     string            `json:"note"`
+	Date     string            `json:"date"`
+}
+
+// StockMovementsPage ...
+type StockMovementsPage struct {
+	Data       []StockMovement `json:"data"`
+	Total      int             `json:"total"`
+	NextCursor string          `json:"nextCursor"`
+}
+
+// Batch - Units of a candy produced together, sales take them from the batch that expires first
+type Batch struct {
+	ID             infra.ObjectID `json:"id"`
+	CandyID        infra.ObjectID `json:"candyId"`
+	CandyName      string         `json:"candyName"`
+	Produced       int            `json:"produced"`
+	Remaining      int            `json:"remaining"`
+	WrittenOff     int            `json:"writtenOff"`
+	ProductionDate string         `json:"productionDate"`
+	ExpiryDate     string         `json:"expiryDate"`
+	WrittenOffAt   string         `json:"writtenOffAt,omitempty"`
+	DaysToExpiry   int            `json:"daysToExpiry"`
+}
+
+// BatchConsumption - How many units of an order item were taken from a batch
+type BatchConsumption struct {
+	BatchID     infra.ObjectID `json:"batchId"`
+	OrderItemID infra.ObjectID `json:"orderItemId"`
+	Quantity    int            `json:"quantity"`
+}
+
+// ExpiringBatches - The batches with units left that expire within the given days
+type ExpiringBatches struct {
+	Days  int `json:"days"`
+	Units int `json:"units"`
+
+	Batches []Batch `json:"batches"`
+}
+
+// WrittenOffBatches - The batches written off as expired between from and to
+type WrittenOffBatches struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Units int    `json:"units"`
+
+	Batches []Batch `json:"batches"`
+}
+
+// Duty - A selling shift, the orders and payments registered while it's open belong to it
+type Duty struct {
+	ID              infra.ObjectID `json:"id"`
+	Open            bool           `json:"open"`
+	OpenedAt        string         `json:"openedAt"`
+	ClosedAt        string         `json:"closedAt,omitempty"`
+	OpeningCash     int            `json:"openingCash"`
+	CountedCash     int            `json:"countedCash"`
+	CountedTransfer int            `json:"countedTransfer"`
+	Note            string         `json:"note"`
+}
+
+// DutyReconciliation - What should have been received in a payment method during the duty, and what was counted.
+// Sales are the orders paid on the spot, payments are the ones received for older orders.
+type DutyReconciliation struct {
+	PaymentMethod PaymentMethod `json:"paymentMethod"`
+	Opening       int           `json:"opening"`
+	Sales         int           `json:"sales"`
+	Payments      int           `json:"payments"`
+	Expected      int           `json:"expected"`
+	Counted       int           `json:"counted"`
+	Difference    int           `json:"difference"`
+}
+
+// DutySummary ...
+type DutySummary struct {
+	Duty
+
+	Orders     int `json:"orders"`
+	Total      int `json:"total"`
+	Scheduled  int `json:"scheduled"`
+	Difference int `json:"difference"`
+
+	Reconciliation []DutyReconciliation `json:"reconciliation"`
+}
+
+// CandyProfit - What a candy earned in a period. The margin rate is the gross margin over the revenue.
+type CandyProfit struct {
+	CandyID     infra.ObjectID `json:"candyId"`
+	CandyName   string         `json:"candyName"`
+	Quantity    int            `json:"quantity"`
+	Revenue     int            `json:"revenue"`
+	Cost        int            `json:"cost"`
+	GrossMargin int            `json:"grossMargin"`
+	MarginRate  float64        `json:"marginRate"`
+}
+
+// ProfitReport - What was earned between from and to, paid or not, using the cost each item had when sold
+type ProfitReport struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Revenue     int     `json:"revenue"`
+	Cost        int     `json:"cost"`
+	GrossMargin int     `json:"grossMargin"`
+	MarginRate  float64 `json:"marginRate"`
+
+	Candies []CandyProfit `json:"candies"`
+}
+
+// Ingredient - Bought by the package, its package size and the recipe quantities are measured in its unit
+type Ingredient struct {
+	ID           infra.ObjectID `json:"id"`
+	Name         string         `json:"name"`
+	Unit         string         `json:"unit"`
+	PackageSize  float64        `json:"packageSize"`
+	PackagePrice int            `json:"packagePrice"`
+}
+
+// RecipeItem - How much of an ingredient the recipe takes, and what that costs at the current package price
+type RecipeItem struct {
+	IngredientID   infra.ObjectID `json:"ingredientId"`
+	IngredientName string         `json:"ingredientName"`
+	Unit           string         `json:"unit"`
+	Quantity       float64        `json:"quantity"`
+	Cost           int            `json:"cost"`
+}
+
+// Recipe - The ingredients that make yield candies. The candy unit cost is derived from it.
+type Recipe struct {
+	CandyID   infra.ObjectID `json:"candyId"`
+	CandyName string         `json:"candyName"`
+	Yield     int            `json:"yield"`
+	Cost      int            `json:"cost"`
+	UnitCost  int            `json:"unitCost"`
+
+	Items []RecipeItem `json:"items"`
+}
+
+// PlannedBatch - How many units of a candy are going to be produced
+type PlannedBatch struct {
+	CandyID  infra.ObjectID `json:"candyId"`
+	Quantity int            `json:"quantity"`
+}
+
+// ShoppingListItem - How much of an ingredient the planned batches need, in whole packages
+type ShoppingListItem struct {
+	IngredientID   infra.ObjectID `json:"ingredientId"`
+	IngredientName string         `json:"ingredientName"`
+	Unit           string         `json:"unit"`
+	Quantity       float64        `json:"quantity"`
+	Packages       int            `json:"packages"`
+	Cost           int            `json:"cost"`
+}
+
+// ShoppingList ...
+type ShoppingList struct {
+	Batches []PlannedBatch     `json:"batches"`
+	Items   []ShoppingListItem `json:"items"`
+	Total   int                `json:"total"`
+}
+
+// Expense - Money spent on the business besides what the candies cost to make
+type Expense struct {
+	ID             infra.ObjectID  `json:"id"`
+	Category       ExpenseCategory `json:"category"`
+	Amount         int             `json:"amount"`
+	Date           string          `json:"date"`
+	Note           string          `json:"note"`
+	AttachmentPath string          `json:"attachmentPath,omitempty"`
+}
+
+// ExpensesPage - A page of expenses. The amount adds up every expense matching the filter, not only the page.
+type ExpensesPage struct {
+	Data       []Expense `json:"data"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"nextCursor"`
+
+	Amount int `json:"amount"`
+}
+
+// ExpenseCategoryTotal ...
+type ExpenseCategoryTotal struct {
+	Category ExpenseCategory `json:"category"`
+	Amount   int             `json:"amount"`
+}
+
+// MonthResult - What a month earned: the revenue of its sales, minus what the candies cost, minus the expenses
+type MonthResult struct {
+	Month       int `json:"month"`
+	Year        int `json:"year"`
+	Revenue     int `json:"revenue"`
+	CostOfGoods int `json:"costOfGoods"`
+	GrossMargin int `json:"grossMargin"`
+	Expenses    int `json:"expenses"`
+	NetProfit   int `json:"netProfit"`
+
+	ExpensesByCategory []ExpenseCategoryTotal `json:"expensesByCategory"`
+}
+
+// MonthComparison - How much each figure changed from the previous month
+type MonthComparison struct {
+	Revenue     int `json:"revenue"`
+	CostOfGoods int `json:"costOfGoods"`
+	GrossMargin int `json:"grossMargin"`
+	Expenses    int `json:"expenses"`
+	NetProfit   int `json:"netProfit"`
+}
+
+// ProfitAndLoss - The month result, compared with the previous month
+type ProfitAndLoss struct {
+	MonthResult
+
+	Previous MonthResult     `json:"previous"`
+	Change   MonthComparison `json:"change"`
+}
+
+// CandyStats - How a candy sold in a period
+type CandyStats struct {
+	Units   int `json:"units"`
+	Revenue int `json:"revenue"`
+}
+
+// PreviousCandyRank - Where the candy was in the previous period, rank 0 when it didn't sell then
+type PreviousCandyRank struct {
+	Rank int `json:"rank"`
+
+	CandyStats
+}
+
+// CandyRank - Candies selling the same share a rank
+type CandyRank struct {
+	Rank      int            `json:"rank"`
+	CandyID   infra.ObjectID `json:"candyId"`
+	CandyName string         `json:"candyName"`
+
+	CandyStats
+
+	Previous *PreviousCandyRank `json:"previous,omitempty"`
+}
+
+// CandiesRanking ...
+type CandiesRanking struct {
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	PreviousFrom string             `json:"previousFrom,omitempty"`
+	PreviousTo   string             `json:"previousTo,omitempty"`
+	Sort         CandiesRankingSort `json:"sort"`
+
+	Candies []CandyRank `json:"candies"`
+}
+
+// CustomerStats - How a customer bought in a period
+type CustomerStats struct {
+	Spend                 int    `json:"spend"`
+	Orders                int    `json:"orders"`
+	LastPurchase          string `json:"lastPurchase"`
+	DaysSinceLastPurchase int    `json:"daysSinceLastPurchase"`
+}
+
+// PreviousCustomerRank - Where the customer was in the previous period, rank 0 when they didn't buy then
+type PreviousCustomerRank struct {
+	Rank int `json:"rank"`
+
+	CustomerStats
+}
+
+// CustomerRank - Customers buying the same share a rank. The days since the last purchase count up to the period end.
+type CustomerRank struct {
+	Rank         int            `json:"rank"`
+	CustomerID   infra.ObjectID `json:"customerId"`
+	CustomerName string         `json:"customerName"`
+
+	CustomerStats
+
+	Previous *PreviousCustomerRank `json:"previous,omitempty"`
+}
+
+// CustomersRanking ...
+type CustomersRanking struct {
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	PreviousFrom string               `json:"previousFrom,omitempty"`
+	PreviousTo   string               `json:"previousTo,omitempty"`
+	Sort         CustomersRankingSort `json:"sort"`
+
+	Customers []CustomerRank `json:"customers"`
+}
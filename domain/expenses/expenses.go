@@ -0,0 +1,357 @@
+package expenses
+
+import (
+	"context"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// expenseColumns are the columns decoded into domain.Expense, from expenses (e)
+const expenseColumns = `
+	e.id as id,
+	e.category as category,
+	e.amount as amount,
+	e.date::text as date,
+	COALESCE(e.note, '') as note,
+	COALESCE(e.attachment_path, '') as attachmentPath
+`
+
+var expensesSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":     {Expr: "e.id", Type: "integer"},
+		"date":   {Expr: "e.date", Type: "date"},
+		"amount": {Expr: "e.amount", Type: "integer"},
+	},
+	DefaultSort:      "date",
+	DefaultDirection: domain.Descending,
+	ID:               "e.id",
+}
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db    infra.RelationalDatabaseProvider
+	Log   infra.LogProvider
+	Sales domain.SalesRepository
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "expenses.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Sales == nil {
+		err := infra.MissingDependencyError{DependencyName: "Sales"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// Register ...
+func (s Service) Register(ctx context.Context, expenseDTO domain.Expense) (*domain.Expense, *infra.Error) {
+	const opName infra.OpName = "expenses.Register"
+
+	query := `
+		INSERT INTO expenses (category, amount, date, note, attachment_path)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING id
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new expense...", infra.Metadata{
+		"expense": expenseDTO,
+	})
+
+	inserted := struct{ ID infra.ObjectID }{}
+
+	decoder := s.in.Db.Query(ctx, query, expenseDTO.Category, expenseDTO.Amount, expenseDTO.Date, expenseDTO.Note, expenseDTO.AttachmentPath)
+	if err := decoder.Decode(ctx, &inserted); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	expense, err := s.Find(ctx, inserted.ID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return expense, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, expenseID infra.ObjectID) (*domain.Expense, *infra.Error) {
+	const opName infra.OpName = "expenses.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the expense...")
+
+	query := `SELECT ` + expenseColumns + ` FROM expenses e WHERE e.id = $1`
+
+	expense := domain.Expense{}
+	if err := s.in.Db.Query(ctx, query, expenseID).Decode(ctx, &expense); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &expense, nil
+}
+
+// List - Lists a page of the expenses matching the filter, searching by note
+func (s Service) List(ctx context.Context, filter domain.ExpensesFilter, opts domain.QueryOptions) (*domain.ExpensesPage, *infra.Error) {
+	const opName infra.OpName = "expenses.List"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the expenses...", infra.Metadata{
+		"filter":  filter,
+		"options": opts,
+	})
+
+	builder := pagination.Builder{}
+	builder.Search(opts.Search, "e.note")
+
+	if filter.From != "" {
+		builder.Where("e.date >= " + builder.Arg(filter.From) + "::date")
+	}
+
+	if filter.To != "" {
+		builder.Where("e.date <= " + builder.Arg(filter.To) + "::date")
+	}
+
+	if filter.Category != "" {
+		builder.Where("e.category = " + builder.Arg(filter.Category))
+	}
+
+	page, err := builder.Page(ctx, opts, expensesSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	totalsQuery := `
+		SELECT
+			COUNT(*) as total,
+			COALESCE(SUM(e.amount), 0) as amount
+		FROM
+			expenses e
+	` + builder.Filter()
+
+	query := `
+		SELECT ` + expenseColumns + `,
+			` + page.CursorValue + ` as cursorValue
+		FROM
+			expenses e
+	` + page.Where + page.OrderBy
+
+	expenses := domain.ExpensesPage{}
+	if err := s.in.Db.Query(ctx, totalsQuery, builder.Args()...).Decode(ctx, &expenses); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	expenses.Data = []domain.Expense{}
+
+	lastCursorValue := ""
+
+	for cursor.Next(ctx) {
+		row := struct {
+			domain.Expense
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if len(expenses.Data) == page.Limit {
+			last := expenses.Data[len(expenses.Data)-1]
+			expenses.NextCursor = page.NextCursor(lastCursorValue, last.ID)
+			break
+		}
+
+		expenses.Data = append(expenses.Data, row.Expense)
+		lastCursorValue = row.CursorValue
+	}
+
+	return &expenses, nil
+}
+
+// Update ...
+func (s Service) Update(ctx context.Context, expenseID infra.ObjectID, expenseDTO domain.Expense) (*domain.Expense, *infra.Error) {
+	const opName infra.OpName = "expenses.Update"
+
+	query := `
+		UPDATE expenses SET
+			category = $1,
+			amount = $2,
+			date = $3,
+			note = NULLIF($4, ''),
+			attachment_path = NULLIF($5, '')
+		WHERE id = $6
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Updating an expense...", infra.Metadata{
+		"expenseID": expenseID,
+		"dto":       expenseDTO,
+	})
+
+	result, err := s.in.Db.Execute(ctx, query, expenseDTO.Category, expenseDTO.Amount, expenseDTO.Date, expenseDTO.Note, expenseDTO.AttachmentPath, expenseID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	affectedRowsCount, rErr := result.RowsAffected()
+	if rErr != nil {
+		return nil, errors.New(ctx, opName, rErr)
+	}
+
+	if affectedRowsCount < 1 {
+		return nil, errors.New(ctx, opName, "The expense was not found.", infra.KindNotFound)
+	}
+
+	expense, err := s.Find(ctx, expenseID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return expense, nil
+}
+
+// Delete ...
+func (s Service) Delete(ctx context.Context, expenseID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "expenses.Delete"
+
+	query := `DELETE FROM expenses WHERE id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deleting an expense...", infra.Metadata{
+		"expenseID": expenseID,
+	})
+
+	result, err := s.in.Db.Execute(ctx, query, expenseID)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	affectedRowsCount, rErr := result.RowsAffected()
+	if rErr != nil {
+		return errors.New(ctx, opName, rErr)
+	}
+
+	if affectedRowsCount < 1 {
+		return errors.New(ctx, opName, "The expense was not found.", infra.KindNotFound)
+	}
+
+	return nil
+}
+
+// ProfitAndLoss - Puts together the month sales and expenses, and compares them with the previous month
+func (s Service) ProfitAndLoss(ctx context.Context, month int, year int) (*domain.ProfitAndLoss, *infra.Error) {
+	const opName infra.OpName = "expenses.ProfitAndLoss"
+
+	if month < 1 || month > 12 {
+		return nil, errors.New(ctx, opName, "Invalid month.", infra.KindBadRequest, infra.Metadata{
+			"month": month,
+		})
+	}
+
+	s.in.Log.InfoMetadata(ctx, opName, "Putting together the profit and loss...", infra.Metadata{
+		"month": month,
+		"year":  year,
+	})
+
+	current, err := s.monthResult(ctx, month, year)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	previousMonth, previousYear := month-1, year
+	if previousMonth == 0 {
+		previousMonth, previousYear = 12, year-1
+	}
+
+	previous, err := s.monthResult(ctx, previousMonth, previousYear)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &domain.ProfitAndLoss{
+		MonthResult: *current,
+		Previous:    *previous,
+		Change: domain.MonthComparison{
+			Revenue:     current.Revenue - previous.Revenue,
+			CostOfGoods: current.CostOfGoods - previous.CostOfGoods,
+			GrossMargin: current.GrossMargin - previous.GrossMargin,
+			Expenses:    current.Expenses - previous.Expenses,
+			NetProfit:   current.NetProfit - previous.NetProfit,
+		},
+	}, nil
+}
+
+func (s Service) monthResult(ctx context.Context, month int, year int) (*domain.MonthResult, *infra.Error) {
+	const opName infra.OpName = "expenses.monthResult"
+
+	query := `
+		SELECT
+			e.category as category,
+			SUM(e.amount) as amount
+		FROM
+			expenses e
+		WHERE
+			EXTRACT(MONTH FROM e.date) = $1 AND EXTRACT(YEAR FROM e.date) = $2
+		GROUP BY e.category
+		ORDER BY amount DESC, e.category
+	`
+
+	sales, err := s.in.Sales.MonthSales(ctx, month, year)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	result := domain.MonthResult{
+		Month:              month,
+		Year:               year,
+		Revenue:            sales.Subtotal,
+		CostOfGoods:        sales.Cost,
+		GrossMargin:        sales.GrossMargin,
+		ExpensesByCategory: []domain.ExpenseCategoryTotal{},
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, month, year)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		total := domain.ExpenseCategoryTotal{}
+		if err := cursor.Decode(ctx, &total); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		result.ExpensesByCategory = append(result.ExpensesByCategory, total)
+		result.Expenses += total.Amount
+	}
+
+	result.NetProfit = result.GrossMargin - result.Expenses
+
+	return &result, nil
+}
@@ -0,0 +1,233 @@
+package ingredients
+
+import (
+	"context"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// ingredientColumns are the columns decoded into domain.Ingredient, from ingredients (i)
+const ingredientColumns = `
+	i.id as id,
+	i.name as name,
+	i.unit as unit,
+	i.package_size as packageSize,
+	i.package_price as packagePrice
+`
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db      infra.RelationalDatabaseProvider
+	Log     infra.LogProvider
+	Recipes domain.RecipesRepository
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "ingredients.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Recipes == nil {
+		err := infra.MissingDependencyError{DependencyName: "Recipes"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// withDb - Copies the service to query through db, so the ingredient is read and replaced in one transaction
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Register - Registers the ingredient, it fails with a conflict when another one has the same name
+func (s Service) Register(ctx context.Context, ingredientDTO domain.Ingredient) (*domain.Ingredient, *infra.Error) {
+	const opName infra.OpName = "ingredients.Register"
+
+	query := `
+		INSERT INTO ingredients (name, unit, package_size, package_price) VALUES ($1, $2, $3, $4)
+		RETURNING id, name, unit, package_size as packageSize, package_price as packagePrice
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new ingredient...", infra.Metadata{
+		"ingredient": ingredientDTO,
+	})
+
+	ingredient := domain.Ingredient{}
+
+	decoder := s.in.Db.Query(ctx, query, ingredientDTO.Name, ingredientDTO.Unit, ingredientDTO.PackageSize, ingredientDTO.PackagePrice)
+	if err := decoder.Decode(ctx, &ingredient); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &ingredient, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, ingredientID infra.ObjectID) (*domain.Ingredient, *infra.Error) {
+	const opName infra.OpName = "ingredients.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the ingredient...")
+
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients i WHERE i.id = $1`
+
+	ingredient := domain.Ingredient{}
+	if err := s.in.Db.Query(ctx, query, ingredientID).Decode(ctx, &ingredient); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &ingredient, nil
+}
+
+// List - Lists the ingredients by name
+func (s Service) List(ctx context.Context) ([]domain.Ingredient, *infra.Error) {
+	const opName infra.OpName = "ingredients.List"
+
+	s.in.Log.Info(ctx, opName, "Listing the ingredients...")
+
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients i ORDER BY i.name, i.id`
+
+	cursor, err := s.in.Db.QueryAll(ctx, query)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	ingredients := []domain.Ingredient{}
+
+	for cursor.Next(ctx) {
+		ingredient := domain.Ingredient{}
+		if err := cursor.Decode(ctx, &ingredient); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		ingredients = append(ingredients, ingredient)
+	}
+
+	return ingredients, nil
+}
+
+// Update - Updates the ingredient. When its package changes, the costs of the candies made with it are derived again.
+func (s Service) Update(ctx context.Context, ingredientID infra.ObjectID, ingredientDTO domain.Ingredient) (*domain.Ingredient, *infra.Error) {
+	const opName infra.OpName = "ingredients.Update"
+
+	query := `
+		UPDATE ingredients SET
+			name = $1,
+			unit = $2,
+			package_size = $3,
+			package_price = $4
+		WHERE id = $5
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Updating an ingredient...", infra.Metadata{
+		"ingredientID": ingredientID,
+		"dto":          ingredientDTO,
+	})
+
+	var ingredient *domain.Ingredient
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		current, err := txService.Find(ctx, ingredientID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Execute(ctx, query, ingredientDTO.Name, ingredientDTO.Unit, ingredientDTO.PackageSize, ingredientDTO.PackagePrice, ingredientID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		updated, err := txService.Find(ctx, ingredientID)
+		if err != nil {
+			return err
+		}
+
+		if updated.PackageSize != current.PackageSize || updated.PackagePrice != current.PackagePrice {
+			if _, err := s.in.Recipes.WithDb(tx).Recost(ctx, ingredientID); err != nil {
+				return err
+			}
+		}
+
+		ingredient = updated
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return ingredient, nil
+}
+
+// Delete - Deletes the ingredient, it fails with a conflict while a recipe uses it
+func (s Service) Delete(ctx context.Context, ingredientID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "ingredients.Delete"
+
+	usesQuery := `SELECT COUNT(*) as recipes FROM recipe_items WHERE ingredient_id = $1`
+	query := `DELETE FROM ingredients WHERE id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deleting an ingredient...", infra.Metadata{
+		"ingredientID": ingredientID,
+	})
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		uses := struct{ Recipes int }{}
+		if err := tx.Query(ctx, usesQuery, ingredientID).Decode(ctx, &uses); err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if uses.Recipes > 0 {
+			return errors.New(ctx, opName, "The ingredient is used by recipes.", infra.KindConflict, infra.Metadata{
+				"recipes": uses.Recipes,
+			})
+		}
+
+		result, err := tx.Execute(ctx, query, ingredientID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		affectedRowsCount, rErr := result.RowsAffected()
+		if rErr != nil {
+			return errors.New(ctx, opName, rErr)
+		}
+
+		if affectedRowsCount < 1 {
+			return errors.New(ctx, opName, "The ingredient was not found.", infra.KindNotFound)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+var movementsSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":   {Expr: "m.id", Type: "integer"},
+		"date": {Expr: "m.date", Type: "date"},
+	},
+	DefaultSort:      "date",
+	DefaultDirection: domain.Descending,
+	ID:               "m.id",
+}
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db       infra.RelationalDatabaseProvider
+	Log      infra.LogProvider
+	Oversell domain.OversellPolicy
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "inventory.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Oversell == "" {
+		in.Oversell = domain.OversellWarn
+	}
+
+	if in.Oversell != domain.OversellWarn && in.Oversell != domain.OversellReject {
+		return nil, errors.New(opName, "Unknown oversell policy.", infra.KindBadRequest, infra.Metadata{
+			"oversell": in.Oversell,
+		})
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// WithDb - Copies the inventory to query through db, so the stock moves in the transaction of the order or batch moving it
+func (s Service) WithDb(db infra.RelationalDatabaseProvider) domain.InventoryRepository {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Move - Records the movement and applies it to the candy stock, returning the resulting level.
+// Production always adds to the stock and sales and waste always remove from it, whatever the quantity sign.
+func (s Service) Move(ctx context.Context, movement domain.StockMovement) (*domain.StockLevel, *infra.Error) {
+	const opName infra.OpName = "inventory.Move"
+
+	quantity := movement.Quantity
+	if quantity < 0 {
+		quantity = -quantity
+	}
+
+	switch movement.Kind {
+	case domain.StockProduction:
+	case domain.StockSale, domain.StockWaste:
+		quantity = -quantity
+	case domain.StockAdjustment:
+		quantity = movement.Quantity
+	default:
+		return nil, errors.New(ctx, opName, "Unknown stock movement kind.", infra.KindBadRequest, infra.Metadata{
+			"kind": movement.Kind,
+		})
+	}
+
+	if quantity == 0 {
+		return nil, errors.New(ctx, opName, "The quantity can't be zero.", infra.KindBadRequest)
+	}
+
+	stockQuery := `
+		UPDATE candies SET stock = stock + $1
+		WHERE id = $2
+		RETURNING
+			id as candyId,
+			name as candyName,
+			stock,
+			low_stock_threshold as lowStockThreshold,
+			stock <= low_stock_threshold as lowStock
+	`
+
+	movementQuery := `
+		INSERT INTO stock_movements (candy_id, kind, quantity, order_id, note, date)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''), COALESCE(NULLIF($6, '')::date, CURRENT_DATE))
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Moving stock...", infra.Metadata{
+		"movement": movement,
+	})
+
+	level := domain.StockLevel{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if err := tx.Query(ctx, stockQuery, quantity, movement.CandyID).Decode(ctx, &level); err != nil {
+			return errors.New(ctx, opName, err, infra.Metadata{
+				"candyId": movement.CandyID,
+			})
+		}
+
+		if quantity < 0 && level.Stock < 0 && s.in.Oversell == domain.OversellReject {
+			return errors.New(ctx, opName, "Not enough stock.", infra.KindConflict, infra.Metadata{
+				"candyId":   movement.CandyID,
+				"available": level.Stock - quantity,
+				"requested": -quantity,
+			})
+		}
+
+		_, err := tx.Execute(ctx, movementQuery, movement.CandyID, movement.Kind, quantity, movement.OrderID, movement.Note, movement.Date)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	if quantity < 0 && level.Stock < 0 {
+		s.in.Log.WarningMetadata(ctx, opName, "The candy was oversold.", infra.Metadata{
+			"candyId": level.CandyID,
+			"stock":   level.Stock,
+		})
+	}
+
+	return &level, nil
+}
+
+// Movements - Lists a page of the candy stock movements
+func (s Service) Movements(ctx context.Context, candyID infra.ObjectID, opts domain.QueryOptions) (*domain.StockMovementsPage, *infra.Error) {
+	const opName infra.OpName = "inventory.Movements"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the stock movements...", infra.Metadata{
+		"candyId": candyID,
+		"options": opts,
+	})
+
+	builder := pagination.Builder{}
+	builder.Where("m.candy_id = " + builder.Arg(candyID))
+
+	page, err := builder.Page(ctx, opts, movementsSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	countQuery := `SELECT COUNT(*) as total FROM stock_movements m` + builder.Filter()
+
+	query := `
+		SELECT
+			m.id as id,
+			m.candy_id as candyId,
+			m.kind as kind,
+			m.quantity as quantity,
+			COALESCE(m.order_id, 0) as orderId,
+			COALESCE(m.note, '') as note,
+			m.date::text as date,
+			` + page.CursorValue + ` as cursorValue
+		FROM
+			stock_movements m
+	` + page.Where + page.OrderBy
+
+	count := struct{ Total int }{}
+	if err := s.in.Db.Query(ctx, countQuery, builder.Args()...).Decode(ctx, &count); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	movements := domain.StockMovementsPage{
+		Data:  []domain.StockMovement{},
+		Total: count.Total,
+	}
+
+	lastCursorValue := ""
+
+	for cursor.Next(ctx) {
+		row := struct {
+			domain.StockMovement
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if len(movements.Data) == page.Limit {
+			last := movements.Data[len(movements.Data)-1]
+			movements.NextCursor = page.NextCursor(lastCursorValue, last.ID)
+			break
+		}
+
+		movements.Data = append(movements.Data, row.StockMovement)
+		lastCursorValue = row.CursorValue
+	}
+
+	return &movements, nil
+}
+
+// Levels - Lists the stock of every candy, the ones running low first
+func (s Service) Levels(ctx context.Context) (*domain.Inventory, *infra.Error) {
+	const opName infra.OpName = "inventory.Levels"
+
+	s.in.Log.Info(ctx, opName, "Listing the stock levels...")
+
+	query := `
+		SELECT
+			ca.id as candyId,
+			ca.name as candyName,
+			ca.stock as stock,
+			ca.low_stock_threshold as lowStockThreshold,
+			ca.stock <= ca.low_stock_threshold as lowStock
+		FROM
+			candies ca
+		ORDER BY lowStock DESC, ca.name, ca.id
+	`
+
+	cursor, err := s.in.Db.QueryAll(ctx, query)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	inventory := domain.Inventory{
+		Levels: []domain.StockLevel{},
+	}
+
+	for cursor.Next(ctx) {
+		level := domain.StockLevel{}
+		if err := cursor.Decode(ctx, &level); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		inventory.Levels = append(inventory.Levels, level)
+
+		if level.LowStock {
+			inventory.LowStockCount++
+		}
+	}
+
+	return &inventory, nil
+}
+
+// SetLowStockThreshold - Sets the stock under which the candy is flagged as running low
+func (s Service) SetLowStockThreshold(ctx context.Context, candyID infra.ObjectID, threshold int) (*domain.StockLevel, *infra.Error) {
+	const opName infra.OpName = "inventory.SetLowStockThreshold"
+
+	query := `
+		UPDATE candies SET low_stock_threshold = $1
+		WHERE id = $2
+		RETURNING
+			id as candyId,
+			name as candyName,
+			stock,
+			low_stock_threshold as lowStockThreshold,
+			stock <= low_stock_threshold as lowStock
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Setting the low stock threshold...", infra.Metadata{
+		"candyId":   candyID,
+		"threshold": threshold,
+	})
+
+	level := domain.StockLevel{}
+	if err := s.in.Db.Query(ctx, query, threshold, candyID).Decode(ctx, &level); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &level, nil
+}
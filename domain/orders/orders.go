@@ -0,0 +1,366 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db        infra.RelationalDatabaseProvider
+	Log       infra.LogProvider
+	Inventory domain.InventoryRepository
+	Batches   domain.BatchesRepository
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "orders.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Inventory == nil {
+		err := infra.MissingDependencyError{DependencyName: "Inventory"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Batches == nil {
+		err := infra.MissingDependencyError{DependencyName: "Batches"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// withDb - Copies the service to query through db, so an order is read and changed in one transaction
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// WithDb - Copies the orders to query through db, so the sales check and change an order in one transaction
+func (s Service) WithDb(db infra.RelationalDatabaseProvider) domain.OrdersRepository {
+	return s.withDb(db)
+}
+
+// Register - Registers the order and takes its items out of the stock and their batches, in the same transaction
+func (s Service) Register(ctx context.Context, orderDTO domain.Order) (*domain.Order, *infra.Error) {
+	const opName infra.OpName = "orders.Register"
+
+	if len(orderDTO.Items) == 0 {
+		return nil, errors.New(ctx, opName, "The order must have at least one item.", infra.KindBadRequest)
+	}
+
+	orderQuery := `
+		INSERT INTO orders (customer_id, status, payment_method, date, duty_id)
+		VALUES ($1, $2, $3, $4, (SELECT id FROM duties WHERE closed_at IS NULL))
+		RETURNING
+			id,
+			customer_id as customerId,
+			status,
+			payment_method as paymentMethod,
+			date::text as date,
+			COALESCE(duty_id, 0) as dutyId
+	`
+
+	// The candy name, price and cost are copied into the item, so later changes on the candy don't rewrite past orders.
+	// The cost is the one in effect on the order date, falling back to the current one for dates before its history.
+	itemQuery := `
+		INSERT INTO order_items (order_id, candy_id, candy_name, unit_price, unit_cost, quantity, discount)
+		SELECT
+			$1::integer, ca.id, ca.name, ca.price,
+			COALESCE((
+				SELECT cc.cost FROM candy_costs cc
+				WHERE cc.candy_id = ca.id AND cc.effective_from <= $5::date
+				ORDER BY cc.effective_from DESC, cc.id DESC
+				LIMIT 1
+			), ca.cost),
+			$3::integer, $4::integer
+		FROM candies ca
+		WHERE ca.id = $2
+		RETURNING
+			id,
+			order_id as orderId,
+			candy_id as candyId,
+			candy_name as candyName,
+			unit_price as unitPrice,
+			unit_cost as unitCost,
+			quantity,
+			discount,
+			unit_price * quantity - discount as total
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new order...", infra.Metadata{
+		"order": orderDTO,
+	})
+
+	order := domain.Order{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		order = domain.Order{Items: []domain.OrderItem{}}
+
+		decoder := tx.Query(ctx, orderQuery, orderDTO.CustomerID, orderDTO.Status, orderDTO.PaymentMethod, orderDTO.Date)
+		if err := decoder.Decode(ctx, &order); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		for _, itemDTO := range orderDTO.Items {
+			decoder := tx.Query(ctx, itemQuery, order.ID, itemDTO.CandyID, itemDTO.Quantity, itemDTO.Discount, order.Date)
+
+			item := domain.OrderItem{}
+			if err := decoder.Decode(ctx, &item); err != nil {
+				return errors.New(ctx, opName, err, infra.Metadata{
+					"candyId": itemDTO.CandyID,
+				})
+			}
+
+			order.Items = append(order.Items, item)
+
+			level, err := s.in.Inventory.WithDb(tx).Move(ctx, domain.StockMovement{
+				CandyID:  item.CandyID,
+				Kind:     domain.StockSale,
+				Quantity: item.Quantity,
+				OrderID:  order.ID,
+				Date:     order.Date,
+			})
+
+			if err != nil {
+				return errors.New(ctx, opName, err)
+			}
+
+			if level.Stock < 0 {
+				order.Warnings = append(order.Warnings, fmt.Sprintf("%s is oversold, its stock is now %d.", level.CandyName, level.Stock))
+			}
+
+			if _, err := s.in.Batches.WithDb(tx).Consume(ctx, item, order.Date); err != nil {
+				return errors.New(ctx, opName, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	sumItems(&order)
+
+	return &order, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, orderID infra.ObjectID) (*domain.Order, *infra.Error) {
+	const opName infra.OpName = "orders.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the order...")
+
+	query := `
+		SELECT
+			o.id as id,
+			o.customer_id as customerId,
+			o.status as status,
+			o.payment_method as paymentMethod,
+			o.date::text as date,
+			COALESCE(o.duty_id, 0) as dutyId
+		FROM
+			orders o
+		WHERE id = $1
+	`
+
+	decoder := s.in.Db.Query(ctx, query, orderID)
+
+	order := domain.Order{}
+	if err := decoder.Decode(ctx, &order); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	items, err := s.items(ctx, orderID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	order.Items = items
+	sumItems(&order)
+
+	return &order, nil
+}
+
+// Update - Updates the order status and payment method, its items can't be changed
+func (s Service) Update(ctx context.Context, orderID infra.ObjectID, orderDTO domain.Order) (*domain.Order, *infra.Error) {
+	const opName infra.OpName = "orders.Update"
+
+	query := `
+		UPDATE orders SET
+			status = $1,
+			payment_method = $2
+		WHERE id = $3
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Updating an order...", infra.Metadata{
+		"orderID": orderID,
+		"dto":     orderDTO,
+	})
+
+	var order *domain.Order
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		if _, err := txService.Find(ctx, orderID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Execute(ctx, query, orderDTO.Status, orderDTO.PaymentMethod, orderID); err != nil {
+			return errors.New(ctx, opName, err, infra.KindBadRequest)
+		}
+
+		updatedOrder, err := txService.Find(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		order = updatedOrder
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return order, nil
+}
+
+// Delete - Deletes the order, putting its items back in the stock and their batches
+func (s Service) Delete(ctx context.Context, orderID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "orders.Delete"
+
+	query := `DELETE from orders WHERE id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deleting an order...", infra.Metadata{
+		"orderID": orderID,
+	})
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		items, err := s.withDb(tx).items(ctx, orderID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		for _, item := range items {
+			_, err := s.in.Inventory.WithDb(tx).Move(ctx, domain.StockMovement{
+				CandyID:  item.CandyID,
+				Kind:     domain.StockAdjustment,
+				Quantity: item.Quantity,
+				Note:     fmt.Sprintf("Order %d deleted", orderID),
+			})
+
+			if err != nil {
+				return errors.New(ctx, opName, err)
+			}
+		}
+
+		// After the units are back in the stock, so the ones of expired batches can be taken out as waste
+		if err := s.in.Batches.WithDb(tx).Restore(ctx, orderID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		result, err := tx.Execute(ctx, query, orderID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		affectedRowsCount, rErr := result.RowsAffected()
+		if rErr != nil {
+			return errors.New(ctx, opName, rErr)
+		}
+
+		if affectedRowsCount < 1 {
+			return errors.New(ctx, opName, "The order was not found.", infra.KindNotFound)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+func (s Service) items(ctx context.Context, orderID infra.ObjectID) ([]domain.OrderItem, *infra.Error) {
+	const opName infra.OpName = "orders.items"
+
+	query := `
+		SELECT
+			i.id as id,
+			i.order_id as orderId,
+			i.candy_id as candyId,
+			i.candy_name as candyName,
+			i.unit_price as unitPrice,
+			i.unit_cost as unitCost,
+			i.quantity as quantity,
+			i.discount as discount,
+			i.unit_price * i.quantity - i.discount as total
+		FROM
+			order_items i
+		WHERE i.order_id = $1
+		ORDER BY i.id
+	`
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, orderID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	items := []domain.OrderItem{}
+
+	for cursor.Next(ctx) {
+		item := domain.OrderItem{}
+		if err := cursor.Decode(ctx, &item); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// sumItems - Fills the order totals from its items
+func sumItems(order *domain.Order) {
+	order.Subtotal = 0
+	order.Discount = 0
+	order.Total = 0
+
+	for _, item := range order.Items {
+		order.Subtotal += item.UnitPrice * item.Quantity
+		order.Discount += item.Discount
+		order.Total += item.Total
+	}
+}
@@ -0,0 +1,226 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+const (
+	// DefaultLimit ...
+	DefaultLimit = 50
+	// MaxLimit ...
+	MaxLimit = 200
+)
+
+// Column - An expression a list can be sorted by, and the postgres type its cursor value is cast back into
+type Column struct {
+	Expr string
+	Type string
+}
+
+// Sorting - The columns a list can be sorted by, and the unique expression that breaks the ties between rows
+type Sorting struct {
+	Columns          map[string]Column
+	DefaultSort      string
+	DefaultDirection domain.SortDirection
+	ID               string
+}
+
+// Builder - Accumulates the conditions of a list query and their args
+type Builder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// Arg - Adds an arg to the query, returning its placeholder
+func (b *Builder) Arg(value interface{}) string {
+	b.args = append(b.args, value)
+
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// Where - Adds a condition to the query, it must use the placeholders returned by Arg
+func (b *Builder) Where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+// Search - Matches the term against any of the expressions, ignoring the case
+func (b *Builder) Search(term string, exprs ...string) {
+	term = strings.TrimSpace(term)
+	if term == "" || len(exprs) == 0 {
+		return
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	placeholder := b.Arg("%" + escaper.Replace(term) + "%")
+
+	matches := make([]string, len(exprs))
+	for i, expr := range exprs {
+		matches[i] = fmt.Sprintf("%s ILIKE %s", expr, placeholder)
+	}
+
+	b.Where("(" + strings.Join(matches, " OR ") + ")")
+}
+
+// Filter - The WHERE clause with every condition added so far, or an empty string when there's none
+func (b Builder) Filter() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// Args ...
+func (b Builder) Args() []interface{} {
+	return b.args
+}
+
+// Page - The clauses of a query that fetches a single page
+type Page struct {
+	// Where - The filters plus the condition that skips the rows before the cursor
+	Where string
+	// OrderBy - The ORDER BY, LIMIT and OFFSET clauses. One row more than Limit is fetched to know if there is a next page.
+	OrderBy string
+	// CursorValue - The sort expression as text, it must be selected as cursorValue to build the next cursor
+	CursorValue string
+	Args        []interface{}
+	Limit       int
+
+	sort      string
+	direction domain.SortDirection
+}
+
+type cursor struct {
+	Sort      string               `json:"s"`
+	Direction domain.SortDirection `json:"d"`
+	Value     string               `json:"v"`
+	ID        infra.ObjectID       `json:"id"`
+}
+
+// Page - Builds the clauses of the page requested by the options on top of the conditions added so far
+func (b Builder) Page(ctx context.Context, opts domain.QueryOptions, sorting Sorting) (*Page, *infra.Error) {
+	const opName infra.OpName = "pagination.Page"
+
+	sort := opts.Sort
+	if sort == "" {
+		sort = sorting.DefaultSort
+	}
+
+	column, ok := sorting.Columns[sort]
+	if !ok {
+		return nil, errors.New(ctx, opName, "Invalid sort field.", infra.KindBadRequest, infra.Metadata{
+			"sort": sort,
+		})
+	}
+
+	direction := opts.Direction
+	if direction == "" {
+		direction = sorting.DefaultDirection
+	}
+
+	if direction == "" {
+		direction = domain.Ascending
+	}
+
+	if direction != domain.Ascending && direction != domain.Descending {
+		return nil, errors.New(ctx, opName, "Invalid sort direction.", infra.KindBadRequest, infra.Metadata{
+			"direction": direction,
+		})
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	// Copies the builder, so the count query can still use the conditions without the cursor
+	page := Builder{
+		conditions: append([]string{}, b.conditions...),
+		args:       append([]interface{}{}, b.args...),
+	}
+
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+		}
+
+		if after.Sort != sort || after.Direction != direction {
+			return nil, errors.New(ctx, opName, "The cursor doesn't match the requested sort.", infra.KindBadRequest, infra.Metadata{
+				"sort":      sort,
+				"direction": direction,
+			})
+		}
+
+		comparison := ">"
+		if direction == domain.Descending {
+			comparison = "<"
+		}
+
+		page.Where(fmt.Sprintf(
+			"(%s, %s) %s (%s::%s, %s::integer)",
+			column.Expr, sorting.ID, comparison, page.Arg(after.Value), column.Type, page.Arg(after.ID),
+		))
+	}
+
+	orderBy := fmt.Sprintf(
+		" ORDER BY %s %s, %s %s LIMIT %s",
+		column.Expr, strings.ToUpper(string(direction)), sorting.ID, strings.ToUpper(string(direction)), page.Arg(limit+1),
+	)
+
+	if opts.Cursor == "" && opts.Offset > 0 {
+		orderBy += " OFFSET " + page.Arg(opts.Offset)
+	}
+
+	return &Page{
+		Where:       page.Filter(),
+		OrderBy:     orderBy,
+		CursorValue: fmt.Sprintf("(%s)::text", column.Expr),
+		Args:        page.Args(),
+		Limit:       limit,
+		sort:        sort,
+		direction:   direction,
+	}, nil
+}
+
+// NextCursor - Encodes the cursor of the page that follows the row with the given cursor value and id
+func (p Page) NextCursor(value string, id infra.ObjectID) string {
+	encoded, err := json.Marshal(cursor{
+		Sort:      p.sort,
+		Direction: p.direction,
+		Value:     value,
+		ID:        id,
+	})
+
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(raw string) (*cursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+
+	c := cursor{}
+	if err := json.Unmarshal(decoded, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %v", err)
+	}
+
+	return &c, nil
+}
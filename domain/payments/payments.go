@@ -0,0 +1,319 @@
+package payments
+
+import (
+	"context"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db  infra.RelationalDatabaseProvider
+	Log infra.LogProvider
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "payments.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// withDb - Copies the service to query through db, so a new payment is read back with its allocations before they are committed
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Register - Registers a payment and allocates it to the customer open orders, oldest first.
+// Orders fully covered are marked as paid, and whatever is left stays as customer credit.
+func (s Service) Register(ctx context.Context, paymentDTO domain.Payment) (*domain.Payment, *infra.Error) {
+	const opName infra.OpName = "payments.Register"
+
+	// Locking the customer serializes concurrent payments, so the same debt isn't allocated twice.
+	lockQuery := `SELECT id FROM customers WHERE id = $1 FOR UPDATE`
+
+	paymentQuery := `
+		INSERT INTO payments (customer_id, amount, payment_method, date, note, duty_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), (SELECT id FROM duties WHERE closed_at IS NULL))
+		RETURNING id
+	`
+
+	openOrdersQuery := `
+		SELECT
+			b.order_id as orderId,
+			b.outstanding as outstanding
+		FROM
+			order_balances b
+		WHERE b.customer_id = $1 AND b.outstanding > 0
+		ORDER BY b.date, b.order_id
+	`
+
+	allocationQuery := `INSERT INTO payment_allocations (payment_id, order_id, amount) VALUES ($1, $2, $3)`
+	settleQuery := `UPDATE orders SET status = $1 WHERE id = $2`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new payment...", infra.Metadata{
+		"payment": paymentDTO,
+	})
+
+	var payment *domain.Payment
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		customer := struct{ ID infra.ObjectID }{}
+		if err := tx.Query(ctx, lockQuery, paymentDTO.CustomerID).Decode(ctx, &customer); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		inserted := struct{ ID infra.ObjectID }{}
+		decoder := tx.Query(ctx, paymentQuery, paymentDTO.CustomerID, paymentDTO.Amount, paymentDTO.PaymentMethod, paymentDTO.Date, paymentDTO.Note)
+		if err := decoder.Decode(ctx, &inserted); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		openOrders, err := openBalances(ctx, tx, openOrdersQuery, paymentDTO.CustomerID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		remaining := paymentDTO.Amount
+
+		for _, order := range openOrders {
+			if remaining == 0 {
+				break
+			}
+
+			amount := order.Outstanding
+			if remaining < amount {
+				amount = remaining
+			}
+
+			if _, err := tx.Execute(ctx, allocationQuery, inserted.ID, order.OrderID, amount); err != nil {
+				return errors.New(ctx, opName, err)
+			}
+
+			if amount == order.Outstanding {
+				if _, err := tx.Execute(ctx, settleQuery, domain.Paid, order.OrderID); err != nil {
+					return errors.New(ctx, opName, err)
+				}
+			}
+
+			remaining -= amount
+		}
+
+		registered, err := s.withDb(tx).Find(ctx, inserted.ID)
+		if err != nil {
+			return err
+		}
+
+		payment = registered
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return payment, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, paymentID infra.ObjectID) (*domain.Payment, *infra.Error) {
+	const opName infra.OpName = "payments.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the payment...")
+
+	query := `
+		SELECT
+			p.id as id,
+			p.customer_id as customerId,
+			p.amount as amount,
+			p.payment_method as paymentMethod,
+			p.date::text as date,
+			COALESCE(p.note, '') as note,
+			COALESCE(p.duty_id, 0) as dutyId
+		FROM
+			payments p
+		WHERE p.id = $1
+	`
+
+	allocationsQuery := `
+		SELECT
+			a.id as id,
+			a.payment_id as paymentId,
+			a.order_id as orderId,
+			a.amount as amount
+		FROM
+			payment_allocations a
+		WHERE a.payment_id = $1
+		ORDER BY a.id
+	`
+
+	payment := domain.Payment{}
+	if err := s.in.Db.Query(ctx, query, paymentID).Decode(ctx, &payment); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, allocationsQuery, paymentID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	payment.Allocations = []domain.PaymentAllocation{}
+	payment.Unallocated = payment.Amount
+
+	for cursor.Next(ctx) {
+		allocation := domain.PaymentAllocation{}
+		if err := cursor.Decode(ctx, &allocation); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		payment.Allocations = append(payment.Allocations, allocation)
+		payment.Unallocated -= allocation.Amount
+	}
+
+	return &payment, nil
+}
+
+// Delete - Deletes the payment, reopening the orders it had settled
+func (s Service) Delete(ctx context.Context, paymentID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "payments.Delete"
+
+	reopenQuery := `
+		UPDATE orders SET status = $1
+		WHERE id IN (SELECT order_id FROM payment_allocations WHERE payment_id = $2)
+	`
+
+	query := `DELETE from payments WHERE id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deleting a payment...", infra.Metadata{
+		"paymentID": paymentID,
+	})
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := tx.Execute(ctx, reopenQuery, domain.NotPaid, paymentID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		result, err := tx.Execute(ctx, query, paymentID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		affectedRowsCount, rErr := result.RowsAffected()
+		if rErr != nil {
+			return errors.New(ctx, opName, rErr)
+		}
+
+		if affectedRowsCount < 1 {
+			return errors.New(ctx, opName, "The payment was not found.", infra.KindNotFound)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// Balance - Computes how much the customer owes, from the orders outstanding amounts and the unallocated payments
+func (s Service) Balance(ctx context.Context, customerID infra.ObjectID) (*domain.CustomerBalance, *infra.Error) {
+	const opName infra.OpName = "payments.Balance"
+
+	s.in.Log.Info(ctx, opName, "Computing the customer balance...")
+
+	query := `
+		SELECT
+			cu.id as customerId,
+			COALESCE((SELECT SUM(b.total) FROM order_balances b WHERE b.customer_id = cu.id), 0) as purchased,
+			COALESCE((SELECT SUM(b.outstanding) FROM order_balances b WHERE b.customer_id = cu.id), 0) as outstanding,
+			COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.customer_id = cu.id), 0)
+				- COALESCE((
+					SELECT SUM(a.amount)
+					FROM payment_allocations a INNER JOIN payments p ON p.id = a.payment_id
+					WHERE p.customer_id = cu.id
+				), 0) as credit
+		FROM
+			customers cu
+		WHERE cu.id = $1
+	`
+
+	openOrdersQuery := `
+		SELECT
+			b.order_id as orderId,
+			b.date::text as date,
+			b.status as status,
+			b.total as total,
+			b.allocated as allocated,
+			b.outstanding as outstanding
+		FROM
+			order_balances b
+		WHERE b.customer_id = $1 AND b.outstanding > 0
+		ORDER BY b.date, b.order_id
+	`
+
+	balance := domain.CustomerBalance{}
+	if err := s.in.Db.Query(ctx, query, customerID).Decode(ctx, &balance); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	openOrders, err := openBalances(ctx, s.in.Db, openOrdersQuery, customerID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	balance.OpenOrders = openOrders
+	balance.Balance = balance.Outstanding - balance.Credit
+
+	return &balance, nil
+}
+
+func openBalances(ctx context.Context, db infra.RelationalDatabaseProvider, query string, customerID infra.ObjectID) ([]domain.OrderBalance, *infra.Error) {
+	const opName infra.OpName = "payments.openBalances"
+
+	cursor, err := db.QueryAll(ctx, query, customerID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	balances := []domain.OrderBalance{}
+
+	for cursor.Next(ctx) {
+		balance := domain.OrderBalance{}
+		if err := cursor.Decode(ctx, &balance); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,18 @@
+package domain
+
+var rolePermissions = map[Role][]Permission{
+	Owner:  {ReadRecords, RegisterSales, ManageRecords, ReadReports, ManageUsers},
+	Seller: {ReadRecords, RegisterSales},
+	Viewer: {ReadRecords},
+}
+
+// Can - Whether the role has the permission, unknown roles have none
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,419 @@
+package recipes
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// itemRow - A recipe item with the package of its ingredient, which its cost is derived from
+type itemRow struct {
+	domain.RecipeItem
+	PackageSize  float64
+	PackagePrice int
+}
+
+// cost - What the item quantity costs at the current package price, not rounded
+func (r itemRow) cost(quantity float64) float64 {
+	return quantity * float64(r.PackagePrice) / r.PackageSize
+}
+
+// ServiceInput ...
+type ServiceInput struct {
+	Db      infra.RelationalDatabaseProvider
+	Log     infra.LogProvider
+	Candies domain.CandiesRepository
+}
+
+// Service ...
+type Service struct {
+	in ServiceInput
+}
+
+// NewService ...
+func NewService(in ServiceInput) (*Service, *infra.Error) {
+	const opName infra.OpName = "recipes.NewService"
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Candies == nil {
+		err := infra.MissingDependencyError{DependencyName: "Candies"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Service{
+		in: in,
+	}, nil
+}
+
+// WithDb - Copies the recipes to query through db, so the ingredients recost the candies in their own transactions
+func (s Service) WithDb(db infra.RelationalDatabaseProvider) domain.RecipesRepository {
+	return s.withDb(db)
+}
+
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
+
+	return Service{in: in}
+}
+
+// Save - Creates or replaces the candy recipe and sets the candy unit cost from it
+func (s Service) Save(ctx context.Context, recipeDTO domain.Recipe) (*domain.Recipe, *infra.Error) {
+	const opName infra.OpName = "recipes.Save"
+
+	if recipeDTO.Yield < 1 {
+		return nil, errors.New(ctx, opName, "The recipe must yield at least one candy.", infra.KindBadRequest)
+	}
+
+	if len(recipeDTO.Items) == 0 {
+		return nil, errors.New(ctx, opName, "The recipe must have at least one ingredient.", infra.KindBadRequest)
+	}
+
+	ingredients := map[infra.ObjectID]bool{}
+
+	for _, item := range recipeDTO.Items {
+		if item.Quantity <= 0 {
+			return nil, errors.New(ctx, opName, "The ingredient quantity must be positive.", infra.KindBadRequest, infra.Metadata{
+				"ingredientId": item.IngredientID,
+			})
+		}
+
+		if ingredients[item.IngredientID] {
+			return nil, errors.New(ctx, opName, "The ingredient is repeated in the recipe.", infra.KindBadRequest, infra.Metadata{
+				"ingredientId": item.IngredientID,
+			})
+		}
+
+		ingredients[item.IngredientID] = true
+	}
+
+	recipeQuery := `
+		INSERT INTO recipes (candy_id, yield) VALUES ($1, $2)
+		ON CONFLICT (candy_id) DO UPDATE SET yield = EXCLUDED.yield
+	`
+
+	clearQuery := `DELETE FROM recipe_items WHERE candy_id = $1`
+
+	itemQuery := `
+		INSERT INTO recipe_items (candy_id, ingredient_id, quantity)
+		SELECT $1::integer, i.id, $3::numeric
+		FROM ingredients i
+		WHERE i.id = $2
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Saving a recipe...", infra.Metadata{
+		"recipe": recipeDTO,
+	})
+
+	var recipe *domain.Recipe
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		candies := s.in.Candies.WithDb(tx)
+
+		if _, err := candies.Find(ctx, recipeDTO.CandyID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Execute(ctx, recipeQuery, recipeDTO.CandyID, recipeDTO.Yield); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		if _, err := tx.Execute(ctx, clearQuery, recipeDTO.CandyID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		for _, item := range recipeDTO.Items {
+			result, err := tx.Execute(ctx, itemQuery, recipeDTO.CandyID, item.IngredientID, item.Quantity)
+			if err != nil {
+				return errors.New(ctx, opName, err)
+			}
+
+			affectedRowsCount, rErr := result.RowsAffected()
+			if rErr != nil {
+				return errors.New(ctx, opName, rErr)
+			}
+
+			if affectedRowsCount < 1 {
+				return errors.New(ctx, opName, "The ingredient was not found.", infra.KindNotFound, infra.Metadata{
+					"ingredientId": item.IngredientID,
+				})
+			}
+		}
+
+		saved, err := s.withDb(tx).Find(ctx, recipeDTO.CandyID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := candies.SetCost(ctx, saved.CandyID, saved.UnitCost); err != nil {
+			return err
+		}
+
+		recipe = saved
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return recipe, nil
+}
+
+// Find - Finds the candy recipe, costing it with the current ingredient prices
+func (s Service) Find(ctx context.Context, candyID infra.ObjectID) (*domain.Recipe, *infra.Error) {
+	const opName infra.OpName = "recipes.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the recipe...")
+
+	recipe, _, err := s.find(ctx, candyID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return recipe, nil
+}
+
+// Delete - Deletes the candy recipe, the candy keeps its last unit cost
+func (s Service) Delete(ctx context.Context, candyID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "recipes.Delete"
+
+	query := `DELETE FROM recipes WHERE candy_id = $1`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deleting a recipe...", infra.Metadata{
+		"candyID": candyID,
+	})
+
+	result, err := s.in.Db.Execute(ctx, query, candyID)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	affectedRowsCount, rErr := result.RowsAffected()
+	if rErr != nil {
+		return errors.New(ctx, opName, rErr)
+	}
+
+	if affectedRowsCount < 1 {
+		return errors.New(ctx, opName, "The recipe was not found.", infra.KindNotFound)
+	}
+
+	return nil
+}
+
+// Recost - Sets again the unit cost of the candies whose recipes use the ingredient, returning the candies
+func (s Service) Recost(ctx context.Context, ingredientID infra.ObjectID) ([]domain.Candy, *infra.Error) {
+	const opName infra.OpName = "recipes.Recost"
+
+	query := `SELECT DISTINCT candy_id as id FROM recipe_items WHERE ingredient_id = $1 ORDER BY candy_id`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Recosting the recipes...", infra.Metadata{
+		"ingredientID": ingredientID,
+	})
+
+	candies := []domain.Candy{}
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		candies = []domain.Candy{}
+
+		cursor, err := tx.QueryAll(ctx, query, ingredientID)
+		if err != nil {
+			return errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		candyIDs := []infra.ObjectID{}
+
+		for cursor.Next(ctx) {
+			row := struct{ ID infra.ObjectID }{}
+			if err := cursor.Decode(ctx, &row); err != nil {
+				cursor.Close(ctx)
+				return errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			candyIDs = append(candyIDs, row.ID)
+		}
+
+		cursor.Close(ctx)
+
+		txService := s.withDb(tx)
+
+		for _, candyID := range candyIDs {
+			recipe, err := txService.Find(ctx, candyID)
+			if err != nil {
+				return err
+			}
+
+			candy, err := s.in.Candies.WithDb(tx).SetCost(ctx, candyID, recipe.UnitCost)
+			if err != nil {
+				return err
+			}
+
+			candies = append(candies, *candy)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return candies, nil
+}
+
+// ShoppingList - Sums the ingredients the planned batches need, scaling each recipe by the units over its yield.
+// Ingredients are bought by the package, so the cost is of the whole packages needed.
+func (s Service) ShoppingList(ctx context.Context, batches []domain.PlannedBatch) (*domain.ShoppingList, *infra.Error) {
+	const opName infra.OpName = "recipes.ShoppingList"
+
+	if len(batches) == 0 {
+		return nil, errors.New(ctx, opName, "At least one batch must be planned.", infra.KindBadRequest)
+	}
+
+	s.in.Log.InfoMetadata(ctx, opName, "Computing the shopping list...", infra.Metadata{
+		"batches": batches,
+	})
+
+	needed := map[infra.ObjectID]*itemRow{}
+
+	for _, batch := range batches {
+		if batch.Quantity < 1 {
+			return nil, errors.New(ctx, opName, "The batch must produce at least one unit.", infra.KindBadRequest, infra.Metadata{
+				"candyId": batch.CandyID,
+			})
+		}
+
+		recipe, items, err := s.find(ctx, batch.CandyID)
+		if err != nil {
+			return nil, errors.New(ctx, opName, err, infra.Metadata{
+				"candyId": batch.CandyID,
+			})
+		}
+
+		for _, item := range items {
+			quantity := item.Quantity * float64(batch.Quantity) / float64(recipe.Yield)
+
+			if _, ok := needed[item.IngredientID]; !ok {
+				row := item
+				row.Quantity = 0
+				needed[item.IngredientID] = &row
+			}
+
+			needed[item.IngredientID].Quantity += quantity
+		}
+	}
+
+	list := domain.ShoppingList{
+		Batches: batches,
+		Items:   []domain.ShoppingListItem{},
+	}
+
+	for _, row := range needed {
+		quantity := math.Round(row.Quantity*1000) / 1000
+		packages := int(math.Ceil(quantity / row.PackageSize))
+
+		item := domain.ShoppingListItem{
+			IngredientID:   row.IngredientID,
+			IngredientName: row.IngredientName,
+			Unit:           row.Unit,
+			Quantity:       quantity,
+			Packages:       packages,
+			Cost:           packages * row.PackagePrice,
+		}
+
+		list.Items = append(list.Items, item)
+		list.Total += item.Cost
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].IngredientName == list.Items[j].IngredientName {
+			return list.Items[i].IngredientID < list.Items[j].IngredientID
+		}
+
+		return list.Items[i].IngredientName < list.Items[j].IngredientName
+	})
+
+	return &list, nil
+}
+
+// find - Finds the candy recipe along with the packages of its ingredients
+func (s Service) find(ctx context.Context, candyID infra.ObjectID) (*domain.Recipe, []itemRow, *infra.Error) {
+	const opName infra.OpName = "recipes.find"
+
+	recipeQuery := `
+		SELECT
+			r.candy_id as candyId,
+			ca.name as candyName,
+			r.yield as yield
+		FROM
+			recipes r
+			INNER JOIN candies ca ON ca.id = r.candy_id
+		WHERE r.candy_id = $1
+	`
+
+	itemsQuery := `
+		SELECT
+			ri.ingredient_id as ingredientId,
+			i.name as ingredientName,
+			i.unit as unit,
+			ri.quantity as quantity,
+			i.package_size as packageSize,
+			i.package_price as packagePrice
+		FROM
+			recipe_items ri
+			INNER JOIN ingredients i ON i.id = ri.ingredient_id
+		WHERE ri.candy_id = $1
+		ORDER BY i.name, i.id
+	`
+
+	recipe := domain.Recipe{
+		Items: []domain.RecipeItem{},
+	}
+
+	if err := s.in.Db.Query(ctx, recipeQuery, candyID).Decode(ctx, &recipe); err != nil {
+		return nil, nil, errors.New(ctx, opName, err)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, itemsQuery, candyID)
+	if err != nil {
+		return nil, nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	rows := []itemRow{}
+	cost := 0.0
+
+	for cursor.Next(ctx) {
+		row := itemRow{}
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		itemCost := row.cost(row.Quantity)
+		row.Cost = int(math.Round(itemCost))
+		cost += itemCost
+
+		rows = append(rows, row)
+		recipe.Items = append(recipe.Items, row.RecipeItem)
+	}
+
+	recipe.Cost = int(math.Round(cost))
+	recipe.UnitCost = int(math.Round(cost / float64(recipe.Yield)))
+
+	return &recipe, rows, nil
+}
@@ -2,16 +2,56 @@ package sales
 
 import (
 	"context"
+	"math"
+	"time"
 
 	"github.com/lucasmls/backend-cacautime/domain"
+	"github.com/lucasmls/backend-cacautime/domain/pagination"
 	"github.com/lucasmls/backend-cacautime/infra"
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+// dateLayout is the format of the dates the rankings are asked for
+const dateLayout = "2006-01-02"
+
+// monthLayout is the format of the months the summary is asked for
+const monthLayout = "2006-01"
+
+var candiesRankingOrderBy = map[domain.CandiesRankingSort]string{
+	domain.CandiesByUnits:   "units DESC, revenue DESC",
+	domain.CandiesByRevenue: "revenue DESC, units DESC",
+}
+
+var customersRankingOrderBy = map[domain.CustomersRankingSort]string{
+	domain.CustomersBySpend:     "spend DESC, orders DESC",
+	domain.CustomersByFrequency: "orders DESC, spend DESC",
+	domain.CustomersByRecency:   "daysSinceLastPurchase ASC, spend DESC",
+}
+
+var debtorsOrderBy = map[domain.DebtorsSort]string{
+	domain.DebtorsByAmount: "outstanding DESC",
+	domain.DebtorsByAge:    "oldestUnpaidDays DESC",
+}
+
+// salesSorting - Sales are listed one row per order item, so the item id breaks the ties
+var salesSorting = pagination.Sorting{
+	Columns: map[string]pagination.Column{
+		"id":       {Expr: "i.id", Type: "integer"},
+		"date":     {Expr: "o.date", Type: "date"},
+		"amount":   {Expr: "i.unit_price * i.quantity - i.discount", Type: "integer"},
+		"customer": {Expr: "cu.name", Type: "text"},
+		"candy":    {Expr: "i.candy_name", Type: "text"},
+	},
+	DefaultSort:      "date",
+	DefaultDirection: domain.Descending,
+	ID:               "i.id",
+}
+
 // ServiceInput ...
 type ServiceInput struct {
-	Db  infra.RelationalDatabaseProvider
-	Log infra.LogProvider
+	Db     infra.RelationalDatabaseProvider
+	Log    infra.LogProvider
+	Orders domain.OrdersRepository
 }
 
 // Service ...
@@ -33,129 +73,239 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.Orders == nil {
+		err := infra.MissingDependencyError{DependencyName: "Orders"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	return &Service{
 		in: in,
 	}, nil
 }
 
-// Register ...
+// Register - Registers the sale as an order with a single item
 func (s Service) Register(ctx context.Context, saleDTO domain.Sale) (*domain.Sale, *infra.Error) {
 	const opName infra.OpName = "sales.Register"
 
-	query := "INSERT INTO sales (customer_id, candy_id, status, payment_method, date) values ($1, $2, $3, $4, $5) RETURNING id, customer_id as customerId, candy_id as candyId, status, payment_method as paymentMethod, date as date"
-
 	s.in.Log.InfoMetadata(ctx, opName, "Registering a new sale...", infra.Metadata{
 		"sale": saleDTO,
 	})
 
-	s.in.Log.DebugMetadata(ctx, opName, "Registering a new sale...", infra.Metadata{
-		"sale":  saleDTO,
-		"query": query,
-	})
-
-	decoder := s.in.Db.Query(ctx, query, saleDTO.CustomerID, saleDTO.CandyID, saleDTO.Status, saleDTO.PaymentMethod, saleDTO.Date)
-	sale := domain.Sale{}
+	orderDTO := domain.Order{
+		CustomerID:    saleDTO.CustomerID,
+		Status:        saleDTO.Status,
+		PaymentMethod: saleDTO.PaymentMethod,
+		Date:          saleDTO.Date,
+		Items: []domain.OrderItem{
+			{
+				CandyID:  saleDTO.CandyID,
+				Quantity: 1,
+			},
+		},
+	}
 
-	if err := decoder.Decode(ctx, &sale); err != nil {
-		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	order, err := s.in.Orders.Register(ctx, orderDTO)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
 	}
 
-	return &sale, nil
+	return saleFromOrder(*order), nil
 }
 
-// Find ...
+// Find - Finds the sale, it fails with a conflict when the order has many items
 func (s Service) Find(ctx context.Context, saleID infra.ObjectID) (*domain.Sale, *infra.Error) {
 	const opName infra.OpName = "sales.Find"
 
 	s.in.Log.Info(ctx, opName, "Fetching the sale...")
 
-	query := `
-		SELECT
-			sa.id as id,
-			sa.customer_id as customerId,
-			sa.candy_id as candyId,
-			sa.payment_method as paymentMethod,
-			sa.status as status,
-			sa.date::text as date
-		FROM
-			sales sa
-		WHERE id = $1
-	`
-
-	decoder := s.in.Db.Query(ctx, query, saleID)
-
-	sale := domain.Sale{}
-	err := decoder.Decode(ctx, &sale)
-
+	order, err := s.singleItemOrder(ctx, s.in.Db, saleID)
 	if err != nil {
 		return nil, errors.New(ctx, opName, err)
 	}
 
-	return &sale, nil
+	return saleFromOrder(*order), nil
 }
 
-// Update ...
+// Update - Updates the sale status and payment method, it fails with a conflict when the order has many items
 func (s Service) Update(ctx context.Context, saleID infra.ObjectID, saleDTO domain.Sale) (*domain.Sale, *infra.Error) {
 	const opName infra.OpName = "sales.Update"
 
-	query := `
-		UPDATE sales SET
-			status = $1,
-			payment_method = $2
-		WHERE id = $3 RETURNING
-			id,
-			customer_id as customerId,
-			candy_id as candyId,
-			status,
-			date::text,
-			payment_method as paymentMethod
-	`
-
 	s.in.Log.InfoMetadata(ctx, opName, "Updating a sale...", infra.Metadata{
 		"saleID": saleID,
 		"dto":    saleDTO,
 	})
 
-	_, err := s.Find(ctx, saleID)
-	if err != nil {
-		return nil, errors.New(ctx, opName, err)
+	orderDTO := domain.Order{
+		Status:        saleDTO.Status,
+		PaymentMethod: saleDTO.PaymentMethod,
 	}
 
-	decoder := s.in.Db.Query(ctx, query, saleDTO.Status, saleDTO.PaymentMethod, saleID)
+	var sale *domain.Sale
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := s.singleItemOrder(ctx, tx, saleID); err != nil {
+			return err
+		}
+
+		order, err := s.in.Orders.WithDb(tx).Update(ctx, saleID, orderDTO)
+		if err != nil {
+			return err
+		}
+
+		sale = saleFromOrder(*order)
+
+		return nil
+	})
 
-	sale := domain.Sale{}
-	if err := decoder.Decode(ctx, &sale); err != nil {
-		return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
 	}
 
-	return &sale, nil
+	return sale, nil
 }
 
-// Delete ...
+// Delete - Deletes the sale, it fails with a conflict when the order has many items
 func (s Service) Delete(ctx context.Context, saleID infra.ObjectID) *infra.Error {
 	const opName infra.OpName = "sales.Delete"
 
-	query := `DELETE from sales WHERE id = $1`
-
 	s.in.Log.InfoMetadata(ctx, opName, "Deleting a sale...", infra.Metadata{
 		"saleID": saleID,
 	})
 
-	result, err := s.in.Db.Execute(ctx, query, saleID)
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := s.singleItemOrder(ctx, tx, saleID); err != nil {
+			return err
+		}
+
+		return s.in.Orders.WithDb(tx).Delete(ctx, saleID)
+	})
+
 	if err != nil {
 		return errors.New(ctx, opName, err)
 	}
 
-	affectedRowsCount, rErr := result.RowsAffected()
-	if rErr != nil {
-		return errors.New(ctx, opName, rErr)
+	return nil
+}
+
+// List - Lists a page of the sales matching the filter, searching by customer or candy name
+func (s Service) List(ctx context.Context, filter domain.SalesFilter, opts domain.QueryOptions) (*domain.SalesPage, *infra.Error) {
+	const opName infra.OpName = "sales.List"
+
+	s.in.Log.InfoMetadata(ctx, opName, "Listing the sales...", infra.Metadata{
+		"filter":  filter,
+		"options": opts,
+	})
+
+	builder := pagination.Builder{}
+	builder.Search(opts.Search, "cu.name", "i.candy_name")
+
+	if filter.From != "" {
+		builder.Where("o.date >= " + builder.Arg(filter.From) + "::date")
 	}
 
-	if affectedRowsCount < 1 {
-		return errors.New(ctx, opName, "The sale was not found.", infra.KindNotFound)
+	if filter.To != "" {
+		builder.Where("o.date <= " + builder.Arg(filter.To) + "::date")
 	}
 
-	return nil
+	if filter.CustomerID != 0 {
+		builder.Where("o.customer_id = " + builder.Arg(filter.CustomerID))
+	}
+
+	if filter.CandyID != 0 {
+		builder.Where("i.candy_id = " + builder.Arg(filter.CandyID))
+	}
+
+	if filter.Status != "" {
+		builder.Where("o.status = " + builder.Arg(filter.Status))
+	}
+
+	if filter.PaymentMethod != "" {
+		builder.Where("o.payment_method = " + builder.Arg(filter.PaymentMethod))
+	}
+
+	page, err := builder.Page(ctx, opts, salesSorting)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	from := `
+		FROM
+			orders o
+			INNER JOIN order_items i ON i.order_id = o.id
+			INNER JOIN customers cu ON o.customer_id = cu.id
+	`
+
+	amount := `i.unit_price * i.quantity - i.discount`
+
+	totalsQuery := `
+		SELECT
+			COUNT(*) as total,
+			COALESCE(SUM(` + amount + `), 0) as subtotal,
+			COALESCE(SUM(` + amount + `) FILTER (WHERE o.status = 'paid'), 0) as paidAmount,
+			COALESCE(SUM(` + amount + `) FILTER (WHERE o.status = 'not_paid'), 0) as scheduledAmount
+	` + from + builder.Filter()
+
+	query := `
+		SELECT
+			o.id as id,
+			i.id as itemId,
+			o.status as status,
+			o.payment_method as paymentMethod,
+			o.date::text as date,
+
+			cu.id as customerId,
+			cu.name as customerName,
+
+			i.candy_id as candyId,
+			i.candy_name as candyName,
+			i.unit_price as candyPrice,
+			i.unit_cost as candyCost,
+			i.quantity as quantity,
+			i.discount as discount,
+			` + amount + ` as amount,
+			i.unit_cost * i.quantity as cost,
+			` + page.CursorValue + ` as cursorValue
+	` + from + page.Where + page.OrderBy
+
+	sales := domain.SalesPage{}
+	if err := s.in.Db.Query(ctx, totalsQuery, builder.Args()...).Decode(ctx, &sales); err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, page.Args...)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	sales.Data = []domain.MonthSale{}
+
+	lastCursorValue := ""
+
+	for cursor.Next(ctx) {
+		row := struct {
+			domain.MonthSale
+			CursorValue string
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		if len(sales.Data) == page.Limit {
+			last := sales.Data[len(sales.Data)-1]
+			sales.NextCursor = page.NextCursor(lastCursorValue, last.ItemID)
+			break
+		}
+
+		row.Profit = row.Amount - row.Cost
+
+		sales.Data = append(sales.Data, row.MonthSale)
+		lastCursorValue = row.CursorValue
+	}
+
+	return &sales, nil
 }
 
 // Months ...
@@ -168,7 +318,7 @@ func (s Service) Months(ctx context.Context) ([]domain.Month, *infra.Error) {
 				trim(to_char(date, 'Month')) as month,
 				trim(to_char(date, 'MM')) as number,
 				trim(to_char(date, 'YYYY')) as year
-			FROM sales
+			FROM orders
 			GROUP BY 1, 2, 3
 		)
 		SELECT *
@@ -206,24 +356,30 @@ func (s Service) MonthSales(ctx context.Context, month int, year int) (*domain.M
 
 	query := `
 		SELECT
-			s.id as id,
-			s.status as status,
-			s.payment_method as paymentMethod,
-			trim(to_char(s.date, 'DD/MM/YYYY')) as date,
+			o.id as id,
+			i.id as itemId,
+			o.status as status,
+			o.payment_method as paymentMethod,
+			trim(to_char(o.date, 'DD/MM/YYYY')) as date,
 			
 			cu.id as customerId,
 			cu.name as customerName,
 		
-			ca.id as candyId,
-			ca.name as candyName,
-			ca.price as candyPrice
+			i.candy_id as candyId,
+			i.candy_name as candyName,
+			i.unit_price as candyPrice,
+			i.unit_cost as candyCost,
+			i.quantity as quantity,
+			i.discount as discount,
+			i.unit_price * i.quantity - i.discount as amount,
+			i.unit_cost * i.quantity as cost
 		FROM
-			sales s
-			INNER JOIN customers cu ON s.customer_id = cu.id
-			INNER JOIN candies ca ON s.candy_id = ca.id
+			orders o
+			INNER JOIN order_items i ON i.order_id = o.id
+			INNER JOIN customers cu ON o.customer_id = cu.id
 		WHERE
-			EXTRACT(MONTH FROM s.date) = $1 and EXTRACT(YEAR FROM s.date) = $2
-		ORDER BY s.created_at;
+			EXTRACT(MONTH FROM o.date) = $1 and EXTRACT(YEAR FROM o.date) = $2
+		ORDER BY o.created_at, i.id;
 	`
 
 	cursor, dbErr := s.in.Db.QueryAll(ctx, query, month, year)
@@ -246,17 +402,525 @@ func (s Service) MonthSales(ctx context.Context, month int, year int) (*domain.M
 			return nil, errors.New(ctx, opName, err, infra.KindBadRequest)
 		}
 
+		sale.Profit = sale.Amount - sale.Cost
+
 		monthSales.Sales = append(monthSales.Sales, sale)
-		monthSales.Subtotal += sale.CandyPrice
+		monthSales.Subtotal += sale.Amount
+		monthSales.Cost += sale.Cost
+		monthSales.GrossMargin += sale.Profit
 
 		if sale.Status == domain.Paid {
-			monthSales.PaidAmount += sale.CandyPrice
+			monthSales.PaidAmount += sale.Amount
 		}
 
 		if sale.Status == domain.NotPaid {
-			monthSales.ScheduledAmount += sale.CandyPrice
+			monthSales.ScheduledAmount += sale.Amount
 		}
 	}
 
 	return &monthSales, nil
 }
+
+// Summary - Adds up the sales of every month between from and to (YYYY-MM, inclusive) and of their years,
+// in a single aggregation so the customers of a year are counted once even when they bought in several months.
+func (s Service) Summary(ctx context.Context, from string, to string) (*domain.SalesSummary, *infra.Error) {
+	const opName infra.OpName = "sales.Summary"
+
+	start, pErr := time.Parse(monthLayout, from)
+	if pErr != nil {
+		return nil, errors.New(ctx, opName, pErr, infra.KindBadRequest, infra.Metadata{
+			"from": from,
+		})
+	}
+
+	end, pErr := time.Parse(monthLayout, to)
+	if pErr != nil || end.Before(start) {
+		return nil, errors.New(ctx, opName, "Invalid summary period.", infra.KindBadRequest, infra.Metadata{
+			"from": from,
+			"to":   to,
+		})
+	}
+
+	// The year rows have no month, so they come as month 0
+	query := `
+		SELECT
+			EXTRACT(YEAR FROM b.date)::integer as year,
+			COALESCE(EXTRACT(MONTH FROM b.date)::integer, 0) as month,
+			SUM(b.total) as subtotal,
+			COALESCE(SUM(b.total) FILTER (WHERE b.status = 'paid'), 0) as paidAmount,
+			COALESCE(SUM(b.total) FILTER (WHERE b.status = 'not_paid'), 0) as scheduledAmount,
+			COUNT(*) as sales,
+			COUNT(DISTINCT b.customer_id) as customers,
+			ROUND(AVG(b.total))::integer as averageTicket
+		FROM
+			order_balances b
+		WHERE
+			b.date >= $1::date AND b.date < $2::date
+		GROUP BY GROUPING SETS (
+			(EXTRACT(YEAR FROM b.date), EXTRACT(MONTH FROM b.date)),
+			(EXTRACT(YEAR FROM b.date))
+		)
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Summarizing the sales...", infra.Metadata{
+		"from": from,
+		"to":   to,
+	})
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, start.Format("2006-01-02"), end.AddDate(0, 1, 0).Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	months := map[[2]int]domain.SalesTotals{}
+
+	for cursor.Next(ctx) {
+		row := struct {
+			Year  int
+			Month int
+			domain.SalesTotals
+		}{}
+
+		if err := cursor.Decode(ctx, &row); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		months[[2]int{row.Year, row.Month}] = row.SalesTotals
+	}
+
+	summary := domain.SalesSummary{
+		From:   from,
+		To:     to,
+		Months: []domain.MonthSummary{},
+		Years:  []domain.YearSummary{},
+	}
+
+	for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+		if len(summary.Years) == 0 || summary.Years[len(summary.Years)-1].Year != month.Year() {
+			summary.Years = append(summary.Years, domain.YearSummary{
+				Year:        month.Year(),
+				SalesTotals: months[[2]int{month.Year(), 0}],
+			})
+		}
+
+		summary.Months = append(summary.Months, domain.MonthSummary{
+			Month:       int(month.Month()),
+			Year:        month.Year(),
+			SalesTotals: months[[2]int{month.Year(), int(month.Month())}],
+		})
+	}
+
+	return &summary, nil
+}
+
+// Debtors - Lists the customers with outstanding orders and how old their debts are
+func (s Service) Debtors(ctx context.Context, sortBy domain.DebtorsSort) (*domain.DebtorsReport, *infra.Error) {
+	const opName infra.OpName = "sales.Debtors"
+
+	orderBy, ok := debtorsOrderBy[sortBy]
+	if !ok {
+		return nil, errors.New(ctx, opName, "Invalid debtors sort.", infra.KindBadRequest, infra.Metadata{
+			"sort": sortBy,
+		})
+	}
+
+	query := `
+		SELECT
+			cu.id as customerId,
+			cu.name as customerName,
+			COALESCE(cu.phone, '') as customerPhone,
+			SUM(b.outstanding) as outstanding,
+			COUNT(*) as unpaidOrders,
+			MIN(b.date)::text as oldestUnpaidDate,
+			MAX(CURRENT_DATE - b.date) as oldestUnpaidDays,
+			SUM(CASE WHEN CURRENT_DATE - b.date <= 30 THEN b.outstanding ELSE 0 END) as upTo30Days,
+			SUM(CASE WHEN CURRENT_DATE - b.date BETWEEN 31 AND 60 THEN b.outstanding ELSE 0 END) as from31To60Days,
+			SUM(CASE WHEN CURRENT_DATE - b.date BETWEEN 61 AND 90 THEN b.outstanding ELSE 0 END) as from61To90Days,
+			SUM(CASE WHEN CURRENT_DATE - b.date > 90 THEN b.outstanding ELSE 0 END) as over90Days
+		FROM
+			order_balances b
+			INNER JOIN customers cu ON b.customer_id = cu.id
+		WHERE
+			b.outstanding > 0
+		GROUP BY cu.id, cu.name, cu.phone
+		ORDER BY ` + orderBy + `, cu.name;
+	`
+
+	s.in.Log.Info(ctx, opName, "Listing the debtors...")
+
+	cursor, err := s.in.Db.QueryAll(ctx, query)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	report := domain.DebtorsReport{
+		Debtors: []domain.Debtor{},
+	}
+
+	for cursor.Next(ctx) {
+		debtor := domain.Debtor{}
+		if err := cursor.Decode(ctx, &debtor); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		report.Debtors = append(report.Debtors, debtor)
+		report.Outstanding += debtor.Outstanding
+		report.UpTo30Days += debtor.UpTo30Days
+		report.From31To60Days += debtor.From31To60Days
+		report.From61To90Days += debtor.From61To90Days
+		report.Over90Days += debtor.Over90Days
+	}
+
+	return &report, nil
+}
+
+// Profit - Sums the revenue and the cost of the items sold between from and to (inclusive), by candy
+func (s Service) Profit(ctx context.Context, from string, to string) (*domain.ProfitReport, *infra.Error) {
+	const opName infra.OpName = "sales.Profit"
+
+	query := `
+		SELECT
+			i.candy_id as candyId,
+			MAX(i.candy_name) as candyName,
+			SUM(i.quantity) as quantity,
+			SUM(i.unit_price * i.quantity - i.discount) as revenue,
+			SUM(i.unit_cost * i.quantity) as cost
+		FROM
+			orders o
+			INNER JOIN order_items i ON i.order_id = o.id
+		WHERE
+			o.date BETWEEN $1::date AND $2::date
+		GROUP BY i.candy_id
+		ORDER BY SUM(i.unit_price * i.quantity - i.discount) - SUM(i.unit_cost * i.quantity) DESC, candyName
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Summing the profit...", infra.Metadata{
+		"from": from,
+		"to":   to,
+	})
+
+	cursor, err := s.in.Db.QueryAll(ctx, query, from, to)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	report := domain.ProfitReport{
+		From:    from,
+		To:      to,
+		Candies: []domain.CandyProfit{},
+	}
+
+	for cursor.Next(ctx) {
+		candy := domain.CandyProfit{}
+		if err := cursor.Decode(ctx, &candy); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		candy.GrossMargin = candy.Revenue - candy.Cost
+		candy.MarginRate = marginRate(candy.GrossMargin, candy.Revenue)
+
+		report.Candies = append(report.Candies, candy)
+		report.Revenue += candy.Revenue
+		report.Cost += candy.Cost
+	}
+
+	report.GrossMargin = report.Revenue - report.Cost
+	report.MarginRate = marginRate(report.GrossMargin, report.Revenue)
+
+	return &report, nil
+}
+
+// CandiesRanking - Ranks the candies sold in the period, optionally showing where they were in the previous one
+func (s Service) CandiesRanking(ctx context.Context, sortBy domain.CandiesRankingSort, opts domain.RankingOptions) (*domain.CandiesRanking, *infra.Error) {
+	const opName infra.OpName = "sales.CandiesRanking"
+
+	orderBy, ok := candiesRankingOrderBy[sortBy]
+	if !ok {
+		return nil, errors.New(ctx, opName, "Invalid candies ranking sort.", infra.KindBadRequest, infra.Metadata{
+			"sort": sortBy,
+		})
+	}
+
+	previousFrom, previousTo, err := previousPeriod(ctx, opts)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	// A limit of 0 becomes LIMIT NULL, which ranks every candy
+	query := `
+		SELECT
+			RANK() OVER (ORDER BY ` + orderBy + `) as rank,
+			c.*
+		FROM (
+			SELECT
+				i.candy_id as candyId,
+				MAX(i.candy_name) as candyName,
+				SUM(i.quantity) as units,
+				SUM(i.unit_price * i.quantity - i.discount) as revenue
+			FROM
+				orders o
+				INNER JOIN order_items i ON i.order_id = o.id
+			WHERE
+				o.date BETWEEN $1::date AND $2::date
+			GROUP BY i.candy_id
+		) c
+		ORDER BY rank, candyName
+		LIMIT NULLIF($3::integer, 0)
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Ranking the candies...", infra.Metadata{
+		"sort":    sortBy,
+		"options": opts,
+	})
+
+	rank := func(from string, to string, limit int) ([]domain.CandyRank, *infra.Error) {
+		cursor, err := s.in.Db.QueryAll(ctx, query, from, to, limit)
+		if err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		defer cursor.Close(ctx)
+
+		candies := []domain.CandyRank{}
+
+		for cursor.Next(ctx) {
+			candy := domain.CandyRank{}
+			if err := cursor.Decode(ctx, &candy); err != nil {
+				return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			candies = append(candies, candy)
+		}
+
+		return candies, nil
+	}
+
+	candies, err := rank(opts.From, opts.To, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ranking := domain.CandiesRanking{
+		From:    opts.From,
+		To:      opts.To,
+		Sort:    sortBy,
+		Candies: candies,
+	}
+
+	if !opts.Compare {
+		return &ranking, nil
+	}
+
+	previous, err := rank(previousFrom, previousTo, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRanks := map[infra.ObjectID]domain.PreviousCandyRank{}
+	for _, candy := range previous {
+		previousRanks[candy.CandyID] = domain.PreviousCandyRank{Rank: candy.Rank, CandyStats: candy.CandyStats}
+	}
+
+	ranking.PreviousFrom = previousFrom
+	ranking.PreviousTo = previousTo
+
+	// The candies that didn't sell in the previous period are left without a previous rank
+	for i := range ranking.Candies {
+		if previousRank, ok := previousRanks[ranking.Candies[i].CandyID]; ok {
+			previousRank := previousRank
+			ranking.Candies[i].Previous = &previousRank
+		}
+	}
+
+	return &ranking, nil
+}
+
+// CustomersRanking - Ranks the customers who bought in the period, optionally showing where they were in the previous one
+func (s Service) CustomersRanking(ctx context.Context, sortBy domain.CustomersRankingSort, opts domain.RankingOptions) (*domain.CustomersRanking, *infra.Error) {
+	const opName infra.OpName = "sales.CustomersRanking"
+
+	orderBy, ok := customersRankingOrderBy[sortBy]
+	if !ok {
+		return nil, errors.New(ctx, opName, "Invalid customers ranking sort.", infra.KindBadRequest, infra.Metadata{
+			"sort": sortBy,
+		})
+	}
+
+	previousFrom, previousTo, err := previousPeriod(ctx, opts)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	// A limit of 0 becomes LIMIT NULL, which ranks every customer
+	query := `
+		SELECT
+			RANK() OVER (ORDER BY ` + orderBy + `) as rank,
+			c.*
+		FROM (
+			SELECT
+				cu.id as customerId,
+				cu.name as customerName,
+				SUM(b.total) as spend,
+				COUNT(*) as orders,
+				MAX(b.date)::text as lastPurchase,
+				$2::date - MAX(b.date) as daysSinceLastPurchase
+			FROM
+				order_balances b
+				INNER JOIN customers cu ON b.customer_id = cu.id
+			WHERE
+				b.date BETWEEN $1::date AND $2::date
+			GROUP BY cu.id, cu.name
+		) c
+		ORDER BY rank, customerName
+		LIMIT NULLIF($3::integer, 0)
+	`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Ranking the customers...", infra.Metadata{
+		"sort":    sortBy,
+		"options": opts,
+	})
+
+	rank := func(from string, to string, limit int) ([]domain.CustomerRank, *infra.Error) {
+		cursor, err := s.in.Db.QueryAll(ctx, query, from, to, limit)
+		if err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		defer cursor.Close(ctx)
+
+		customers := []domain.CustomerRank{}
+
+		for cursor.Next(ctx) {
+			customer := domain.CustomerRank{}
+			if err := cursor.Decode(ctx, &customer); err != nil {
+				return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+			}
+
+			customers = append(customers, customer)
+		}
+
+		return customers, nil
+	}
+
+	customers, err := rank(opts.From, opts.To, opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ranking := domain.CustomersRanking{
+		From:      opts.From,
+		To:        opts.To,
+		Sort:      sortBy,
+		Customers: customers,
+	}
+
+	if !opts.Compare {
+		return &ranking, nil
+	}
+
+	previous, err := rank(previousFrom, previousTo, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	previousRanks := map[infra.ObjectID]domain.PreviousCustomerRank{}
+	for _, customer := range previous {
+		previousRanks[customer.CustomerID] = domain.PreviousCustomerRank{Rank: customer.Rank, CustomerStats: customer.CustomerStats}
+	}
+
+	ranking.PreviousFrom = previousFrom
+	ranking.PreviousTo = previousTo
+
+	// The customers who didn't buy in the previous period are left without a previous rank
+	for i := range ranking.Customers {
+		if previousRank, ok := previousRanks[ranking.Customers[i].CustomerID]; ok {
+			previousRank := previousRank
+			ranking.Customers[i].Previous = &previousRank
+		}
+	}
+
+	return &ranking, nil
+}
+
+// previousPeriod - The period as long as the ranked one that ends the day before it starts
+func previousPeriod(ctx context.Context, opts domain.RankingOptions) (string, string, *infra.Error) {
+	const opName infra.OpName = "sales.previousPeriod"
+
+	from, fErr := time.Parse(dateLayout, opts.From)
+	to, tErr := time.Parse(dateLayout, opts.To)
+
+	if fErr != nil || tErr != nil || from.After(to) {
+		return "", "", errors.New(ctx, opName, "Invalid ranking period.", infra.KindBadRequest, infra.Metadata{
+			"from": opts.From,
+			"to":   opts.To,
+		})
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	previousTo := from.AddDate(0, 0, -1)
+	previousFrom := previousTo.AddDate(0, 0, -days)
+
+	return previousFrom.Format(dateLayout), previousTo.Format(dateLayout), nil
+}
+
+// marginRate - The share of the revenue left as margin, rounded to four decimal places
+func marginRate(margin int, revenue int) float64 {
+	if revenue == 0 {
+		return 0
+	}
+
+	return math.Round(float64(margin)/float64(revenue)*10000) / 10000
+}
+
+// singleItemOrder - Finds the order behind the sale through db, locking it until the transaction ends so no item is
+// added before the sale is changed. The orders with many items only fit the order endpoints.
+func (s Service) singleItemOrder(ctx context.Context, db infra.RelationalDatabaseProvider, saleID infra.ObjectID) (*domain.Order, *infra.Error) {
+	const opName infra.OpName = "sales.singleItemOrder"
+
+	lockQuery := `SELECT id FROM orders WHERE id = $1 FOR UPDATE`
+
+	locked := struct{ ID infra.ObjectID }{}
+	if err := db.Query(ctx, lockQuery, saleID).Decode(ctx, &locked); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	order, err := s.in.Orders.WithDb(db).Find(ctx, saleID)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	if len(order.Items) > 1 {
+		return nil, errors.New(ctx, opName, "The sale is an order with many items.", infra.KindConflict, infra.Metadata{
+			"saleID": saleID,
+			"items":  len(order.Items),
+		})
+	}
+
+	return order, nil
+}
+
+// saleFromOrder - Represents an order in the single candy shape used by the sale endpoints
+func saleFromOrder(order domain.Order) *domain.Sale {
+	sale := domain.Sale{
+		ID:            order.ID,
+		CustomerID:    order.CustomerID,
+		Status:        order.Status,
+		PaymentMethod: order.PaymentMethod,
+		Date:          order.Date,
+		Warnings:      order.Warnings,
+	}
+
+	if len(order.Items) > 0 {
+		sale.CandyID = order.Items[0].CandyID
+		sale.CandyName = order.Items[0].CandyName
+		sale.CandyPrice = order.Items[0].UnitPrice
+	}
+
+	return &sale
+}
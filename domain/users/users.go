@@ -8,10 +8,21 @@ import (
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 )
 
+// userColumns are the columns decoded into domain.User, from users (u)
+const userColumns = `
+	u.id as id,
+	u.name as name,
+	u.email as email,
+	u.password as password,
+	u.role as role,
+	u.active as active
+`
+
 // ServiceInput ...
 type ServiceInput struct {
-	Log infra.LogProvider
-	Db  infra.RelationalDatabaseProvider
+	Log    infra.LogProvider
+	Db     infra.RelationalDatabaseProvider
+	Crypto infra.CryptoProvider
 }
 
 // Service ...
@@ -33,28 +44,99 @@ func NewService(in ServiceInput) (*Service, *infra.Error) {
 		return nil, errors.New(err, opName, infra.KindBadRequest)
 	}
 
+	if in.Crypto == nil {
+		err := infra.MissingDependencyError{DependencyName: "Crypto"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	return &Service{
 		in: in,
 	}, nil
 }
 
-// FindByEmail ...
-func (s Service) FindByEmail(ctx context.Context, email string) (*domain.User, *infra.Error) {
-	const opName infra.OpName = "users.Find"
+// withDb - Copies the service to query through db, so the e-mail and owner checks see the change being made
+func (s Service) withDb(db infra.RelationalDatabaseProvider) Service {
+	in := s.in
+	in.Db = db
 
-	s.in.Log.Info(ctx, opName, "Fetching the user...")
+	return Service{in: in}
+}
+
+// Register - Registers the user with the password hashed, it fails with a conflict when the e-mail is taken
+func (s Service) Register(ctx context.Context, userDTO domain.User) (*domain.User, *infra.Error) {
+	const opName infra.OpName = "users.Register"
 
 	query := `
-		SELECT
-			u.id as id,
-			u.name as name,
-			u.email as email,
-			u.password as password
-		FROM
-			users u
-		WHERE email = $1
+		INSERT INTO users (name, email, password, role) VALUES ($1, $2, $3, $4)
+		RETURNING id
 	`
 
+	s.in.Log.InfoMetadata(ctx, opName, "Registering a new user...", infra.Metadata{
+		"name":  userDTO.Name,
+		"email": userDTO.Email,
+		"role":  userDTO.Role,
+	})
+
+	password, err := s.in.Crypto.Hash(ctx, userDTO.Password)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	var user *domain.User
+
+	err = s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		if err := txService.checkEmail(ctx, userDTO.Email, 0); err != nil {
+			return err
+		}
+
+		inserted := struct{ ID infra.ObjectID }{}
+		if err := tx.Query(ctx, query, userDTO.Name, userDTO.Email, string(password), userDTO.Role).Decode(ctx, &inserted); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		registered, err := txService.Find(ctx, inserted.ID)
+		if err != nil {
+			return err
+		}
+
+		user = registered
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return user, nil
+}
+
+// Find ...
+func (s Service) Find(ctx context.Context, userID infra.ObjectID) (*domain.User, *infra.Error) {
+	const opName infra.OpName = "users.Find"
+
+	s.in.Log.Info(ctx, opName, "Fetching the user...")
+
+	query := `SELECT ` + userColumns + ` FROM users u WHERE u.id = $1`
+
+	user := domain.User{}
+	if err := s.in.Db.Query(ctx, query, userID).Decode(ctx, &user); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return &user, nil
+}
+
+// FindByEmail - Finds the active user with the e-mail, ignoring its case
+func (s Service) FindByEmail(ctx context.Context, email string) (*domain.User, *infra.Error) {
+	const opName infra.OpName = "users.FindByEmail"
+
+	s.in.Log.Info(ctx, opName, "Fetching the user...")
+
+	query := `SELECT ` + userColumns + ` FROM users u WHERE lower(u.email) = lower($1) AND u.active`
+
 	decoder := s.in.Db.Query(ctx, query, email)
 
 	user := domain.User{}
@@ -64,3 +146,221 @@ func (s Service) FindByEmail(ctx context.Context, email string) (*domain.User, *
 
 	return &user, nil
 }
+
+// List - Lists the users by name, the inactive ones included
+func (s Service) List(ctx context.Context) ([]domain.User, *infra.Error) {
+	const opName infra.OpName = "users.List"
+
+	s.in.Log.Info(ctx, opName, "Listing the users...")
+
+	query := `SELECT ` + userColumns + ` FROM users u ORDER BY u.name, u.id`
+
+	cursor, err := s.in.Db.QueryAll(ctx, query)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	defer cursor.Close(ctx)
+
+	users := []domain.User{}
+
+	for cursor.Next(ctx) {
+		user := domain.User{}
+		if err := cursor.Decode(ctx, &user); err != nil {
+			return nil, errors.New(ctx, opName, err, infra.KindUnexpected)
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// Update - Updates the user name, e-mail and role, and the password when a new one is given, ending the user
+// sessions when the role or the password changes. It fails with a conflict when it would leave no active owner.
+func (s Service) Update(ctx context.Context, userID infra.ObjectID, userDTO domain.User) (*domain.User, *infra.Error) {
+	const opName infra.OpName = "users.Update"
+
+	query := `
+		UPDATE users SET
+			name = $1,
+			email = $2,
+			password = COALESCE(NULLIF($3, ''), password),
+			role = $4
+		WHERE id = $5
+	`
+	sessionsQuery := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Updating a user...", infra.Metadata{
+		"userID": userID,
+		"name":   userDTO.Name,
+		"email":  userDTO.Email,
+		"role":   userDTO.Role,
+	})
+
+	password := ""
+	if userDTO.Password != "" {
+		hashed, err := s.in.Crypto.Hash(ctx, userDTO.Password)
+		if err != nil {
+			return nil, errors.New(ctx, opName, err)
+		}
+
+		password = string(hashed)
+	}
+
+	var user *domain.User
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		txService := s.withDb(tx)
+
+		if err := txService.lockOwners(ctx); err != nil {
+			return err
+		}
+
+		if err := txService.checkEmail(ctx, userDTO.Email, userID); err != nil {
+			return err
+		}
+
+		current, err := txService.Find(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Execute(ctx, query, userDTO.Name, userDTO.Email, password, userDTO.Role, userID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		affectedRowsCount, rErr := result.RowsAffected()
+		if rErr != nil {
+			return errors.New(ctx, opName, rErr)
+		}
+
+		if affectedRowsCount < 1 {
+			return errors.New(ctx, opName, "The user was not found.", infra.KindNotFound)
+		}
+
+		if current.Role != userDTO.Role || password != "" {
+			if _, err := tx.Execute(ctx, sessionsQuery, userID); err != nil {
+				return errors.New(ctx, opName, err)
+			}
+		}
+
+		if err := txService.checkOwners(ctx); err != nil {
+			return err
+		}
+
+		updated, err := txService.Find(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		user = updated
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	return user, nil
+}
+
+// Deactivate - Keeps the user from logging in again and ends its sessions, its records stay as they are.
+// It fails with a conflict when it would leave no active owner.
+func (s Service) Deactivate(ctx context.Context, userID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "users.Deactivate"
+
+	query := `UPDATE users SET active = false WHERE id = $1`
+	sessionsQuery := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	s.in.Log.InfoMetadata(ctx, opName, "Deactivating a user...", infra.Metadata{
+		"userID": userID,
+	})
+
+	err := s.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if err := s.withDb(tx).lockOwners(ctx); err != nil {
+			return err
+		}
+
+		result, err := tx.Execute(ctx, query, userID)
+		if err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		affectedRowsCount, rErr := result.RowsAffected()
+		if rErr != nil {
+			return errors.New(ctx, opName, rErr)
+		}
+
+		if affectedRowsCount < 1 {
+			return errors.New(ctx, opName, "The user was not found.", infra.KindNotFound)
+		}
+
+		if _, err := tx.Execute(ctx, sessionsQuery, userID); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+
+		return s.withDb(tx).checkOwners(ctx)
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// checkEmail - Fails with a conflict when a user other than userID has the e-mail. The unique index catches the
+// registrations racing this check.
+func (s Service) checkEmail(ctx context.Context, email string, userID infra.ObjectID) *infra.Error {
+	const opName infra.OpName = "users.checkEmail"
+
+	query := `SELECT COUNT(*) as users FROM users WHERE lower(email) = lower($1) AND id <> $2`
+
+	taken := struct{ Users int }{}
+	if err := s.in.Db.Query(ctx, query, email, userID).Decode(ctx, &taken); err != nil {
+		return errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	if taken.Users > 0 {
+		return errors.New(ctx, opName, "There is already a user with this e-mail.", infra.KindConflict, infra.Metadata{
+			"email": email,
+		})
+	}
+
+	return nil
+}
+
+// lockOwners - Locks the active owners until the transaction ends, so the changes that could remove the last one run
+// one at a time and checkOwners counts what the others committed.
+func (s Service) lockOwners(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "users.lockOwners"
+
+	query := `SELECT id FROM users WHERE role = $1 AND active FOR UPDATE`
+
+	if _, err := s.in.Db.Execute(ctx, query, domain.Owner); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+// checkOwners - Fails with a conflict when no active owner is left, nobody could manage the users then
+func (s Service) checkOwners(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "users.checkOwners"
+
+	query := `SELECT COUNT(*) as owners FROM users WHERE role = $1 AND active`
+
+	owners := struct{ Owners int }{}
+	if err := s.in.Db.Query(ctx, query, domain.Owner).Decode(ctx, &owners); err != nil {
+		return errors.New(ctx, opName, err, infra.KindUnexpected)
+	}
+
+	if owners.Owners < 1 {
+		return errors.New(ctx, opName, "There must be at least one active owner.", infra.KindConflict)
+	}
+
+	return nil
+}
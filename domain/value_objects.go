@@ -1,5 +1,9 @@
 package domain
 
+import (
+	"github.com/lucasmls/backend-cacautime/infra"
+)
+
 // Status ...
 type Status string
 
@@ -21,3 +25,188 @@ const (
 	// Scheduled ...
 	Scheduled PaymentMethod = "scheduled"
 )
+
+// DebtorsSort ...
+type DebtorsSort string
+
+const (
+	// DebtorsByAmount ...
+	DebtorsByAmount DebtorsSort = "amount"
+	// DebtorsByAge ...
+	DebtorsByAge DebtorsSort = "age"
+)
+
+// CandiesRankingSort ...
+type CandiesRankingSort string
+
+const (
+	// CandiesByUnits ...
+	CandiesByUnits CandiesRankingSort = "units"
+	// CandiesByRevenue ...
+	CandiesByRevenue CandiesRankingSort = "revenue"
+)
+
+// CustomersRankingSort ...
+type CustomersRankingSort string
+
+const (
+	// CustomersBySpend - Who spent the most
+	CustomersBySpend CustomersRankingSort = "spend"
+	// CustomersByFrequency - Who ordered the most times
+	CustomersByFrequency CustomersRankingSort = "frequency"
+	// CustomersByRecency - Who ordered last
+	CustomersByRecency CustomersRankingSort = "recency"
+)
+
+// RankingOptions - The period ranked, between From and To (YYYY-MM-DD, inclusive), and how many ranks to list.
+// Compare also ranks the previous period of the same length, the one ending the day before From.
+type RankingOptions struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Limit   int    `json:"limit"`
+	Compare bool   `json:"compare"`
+}
+
+// StatementEntryKind ...
+type StatementEntryKind string
+
+const (
+	// StatementSale - An order, which increases what the customer owes
+	StatementSale StatementEntryKind = "sale"
+	// StatementSettlement - The part of an order paid when it was registered
+	StatementSettlement StatementEntryKind = "settlement"
+	// StatementPayment - A payment made by the customer afterwards
+	StatementPayment StatementEntryKind = "payment"
+)
+
+// SortDirection ...
+type SortDirection string
+
+const (
+	// Ascending ...
+	Ascending SortDirection = "asc"
+	// Descending ...
+	Descending SortDirection = "desc"
+)
+
+// QueryOptions - How a list is paginated, sorted and searched.
+// When Cursor is set the page starts right after the row it points to, and Offset is ignored.
+type QueryOptions struct {
+	Limit     int           `json:"limit"`
+	Offset    int           `json:"offset"`
+	Cursor    string        `json:"cursor"`
+	Sort      string        `json:"sort"`
+	Direction SortDirection `json:"direction"`
+	Search    string        `json:"search"`
+}
+
+// SalesFilter - Narrows the sales list, every field is optional and they are combined.
+// From and To are inclusive dates formatted as YYYY-MM-DD.
+type SalesFilter struct {
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	CustomerID    infra.ObjectID `json:"customerId"`
+	CandyID       infra.ObjectID `json:"candyId"`
+	Status        Status         `json:"status"`
+	PaymentMethod PaymentMethod  `json:"paymentMethod"`
+}
+
+// ExpensesFilter - Narrows the expenses list, every field is optional and they are combined.
+// From and To are inclusive dates formatted as YYYY-MM-DD.
+type ExpensesFilter struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Category ExpenseCategory `json:"category"`
+}
+
+// LoginAttemptsFilter - Narrows the login attempts list, every field is optional and they are combined.
+// From and To are inclusive dates formatted as YYYY-MM-DD.
+type LoginAttemptsFilter struct {
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Email  string      `json:"email"`
+	IP     string      `json:"ip"`
+	Result LoginResult `json:"result"`
+}
+
+// StockMovementKind ...
+type StockMovementKind string
+
+const (
+	// StockProduction - Candies made, adds to the stock
+	StockProduction StockMovementKind = "production"
+	// StockSale - Candies sold, removes from the stock
+	StockSale StockMovementKind = "sale"
+	// StockWaste - Candies lost or thrown away, removes from the stock
+	StockWaste StockMovementKind = "waste"
+	// StockAdjustment - A correction after counting the stock, either way
+	StockAdjustment StockMovementKind = "adjustment"
+)
+
+// OversellPolicy - What happens when a movement takes more candies than there are in stock
+type OversellPolicy string
+
+const (
+	// OversellReject - The movement fails, and so does the sale that caused it
+	OversellReject OversellPolicy = "reject"
+	// OversellWarn - The stock goes negative and the sale carries a warning
+	OversellWarn OversellPolicy = "warn"
+)
+
+// ExpenseCategory - There's no category for ingredients, they are accounted for as the cost of the candies sold
+type ExpenseCategory string
+
+const (
+	// ExpensePackaging - Boxes, wrappers and bags
+	ExpensePackaging ExpenseCategory = "packaging"
+	// ExpenseGas - Cooking gas
+	ExpenseGas ExpenseCategory = "gas"
+	// ExpenseDelivery - Taking the orders to the customers
+	ExpenseDelivery ExpenseCategory = "delivery"
+	// ExpenseOther ...
+	ExpenseOther ExpenseCategory = "other"
+)
+
+// Role - What a user is allowed to do, see Role.Can
+type Role string
+
+const (
+	// Owner - Runs the business, can do everything
+	Owner Role = "owner"
+	// Seller - Registers sales, payments and production, and reads the records
+	Seller Role = "seller"
+	// Viewer - Only reads the records
+	Viewer Role = "viewer"
+)
+
+// Permission ...
+type Permission string
+
+const (
+	// ReadRecords - Customers, candies, sales, orders, stock and duties
+	ReadRecords Permission = "records:read"
+	// RegisterSales - Registers and edits customers, sales, orders, payments, duties and production
+	RegisterSales Permission = "sales:register"
+	// ManageRecords - Edits prices, recipes and expenses, and deletes records
+	ManageRecords Permission = "records:manage"
+	// ReadReports - Costs, profit, expenses and the other money reports
+	ReadReports Permission = "reports:read"
+	// ManageUsers ...
+	ManageUsers Permission = "users:manage"
+)
+
+// LoginResult ...
+type LoginResult string
+
+const (
+	// LoginSucceeded ...
+	LoginSucceeded LoginResult = "success"
+	// LoginUnknownEmail - No active user has the e-mail
+	LoginUnknownEmail LoginResult = "unknown_email"
+	// LoginWrongPassword ...
+	LoginWrongPassword LoginResult = "wrong_password"
+	// LoginLocked - Too many failures before it, the password wasn't even checked
+	LoginLocked LoginResult = "locked"
+	// LoginPending - Still checking the password, it counts as a failure of the IP until it's done
+	LoginPending LoginResult = "pending"
+)
@@ -32,6 +32,7 @@ type RelationalDatabaseProvider interface {
 	Query(context.Context, string, ...interface{}) Decoder
 	QueryAll(context.Context, string, ...interface{}) (Cursor, *Error)
 	Execute(context.Context, string, ...interface{}) (driver.Result, *Error)
+	WithTx(context.Context, func(RelationalDatabaseProvider) *Error, ...TxOptions) *Error
 }
 
 // Entity represents an abstraction of an entity in database
@@ -57,6 +58,17 @@ type CryptoProvider interface {
 
 // TokenProvider ...
 type TokenProvider interface {
-	Generate(context.Context, string) (string, *Error)
+	Generate(context.Context, TokenClaims) (string, *Error)
 	Validate(context.Context, string) (*DecodedJWT, *Error)
 }
+
+// ErrorReporter ...
+type ErrorReporter interface {
+	Report(*Error)
+}
+
+// ErrorSink - A destination for the reported errors
+type ErrorSink interface {
+	Send(context.Context, *Error) *Error
+	Close(context.Context) *Error
+}
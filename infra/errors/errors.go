@@ -19,6 +19,10 @@ func New(args ...interface{}) *infra.Error {
 		switch arg := arg.(type) {
 		case context.Context:
 			err.Ctx = arg
+
+			if contextID := arg.Value(infra.IDContextValueKey); contextID != nil {
+				err.Metadata[infra.IDContextValueKey] = contextID
+			}
 		case error:
 			err.Err = arg
 		case infra.OpName:
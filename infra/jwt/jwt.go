@@ -47,13 +47,15 @@ func NewClient(in ClientInput) (*Client, *infra.Error) {
 }
 
 // Generate ...
-func (c Client) Generate(ctx context.Context, userID string) (string, *infra.Error) {
+func (c Client) Generate(ctx context.Context, tokenClaims infra.TokenClaims) (string, *infra.Error) {
 	const opName infra.OpName = "jwt.Generate"
 
 	jwtInstance := jwt.New(jwt.SigningMethodHS256)
 	claims := jwtInstance.Claims.(jwt.MapClaims)
 
-	claims["userID"] = userID
+	claims["userID"] = tokenClaims.UserID
+	claims["role"] = tokenClaims.Role
+	claims["sid"] = tokenClaims.SessionID
 	claims["exp"] = time.Now().Add(time.Hour * time.Duration(c.in.TTL)).Unix()
 
 	token, err := jwtInstance.SignedString([]byte(c.in.Secret))
@@ -86,9 +88,17 @@ func (c Client) Validate(ctx context.Context, tokenToValidate string) (*infra.De
 		return nil, errors.New(ctx, "Invalid token", opName)
 	}
 
+	// Tokens generated before the roles and sessions existed have neither, so they are allowed nothing
+	role, _ := claims["role"].(string)
+	sessionID, _ := claims["sid"].(string)
+
 	decodedJWT := infra.DecodedJWT{
-		UserID: claims["userID"].(string),
-		Exp:    int64(claims["exp"].(float64)),
+		TokenClaims: infra.TokenClaims{
+			UserID:    claims["userID"].(string),
+			Role:      role,
+			SessionID: sessionID,
+		},
+		Exp: int64(claims["exp"].(float64)),
 	}
 
 	return &decodedJWT, nil
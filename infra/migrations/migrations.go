@@ -0,0 +1,430 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// downMarker splits a migration file into its up and down sections.
+const downMarker = "-- migrate:down"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)-(.+)\.sql$`)
+
+// Migration ...
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status ...
+type Status struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"appliedAt,omitempty"`
+}
+
+type appliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt string
+}
+
+// ClientInput ...
+type ClientInput struct {
+	Log infra.LogProvider
+	Db  infra.RelationalDatabaseProvider
+	Dir string
+}
+
+// Client ...
+type Client struct {
+	in ClientInput
+}
+
+// NewClient ...
+func NewClient(in ClientInput) (*Client, *infra.Error) {
+	const opName infra.OpName = "migrations.NewClient"
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Db == nil {
+		err := infra.MissingDependencyError{DependencyName: "Db"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.Dir == "" {
+		err := infra.MissingDependencyError{DependencyName: "Dir"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &Client{
+		in: in,
+	}, nil
+}
+
+// Load - Reads and parses every migration file of the migrations directory, ordered by version
+func (c Client) Load(ctx context.Context) ([]Migration, *infra.Error) {
+	const opName infra.OpName = "migrations.Load"
+
+	files, err := ioutil.ReadDir(c.in.Dir)
+	if err != nil {
+		return nil, errors.New(ctx, err, opName, infra.KindUnexpected)
+	}
+
+	migrations := []Migration{}
+	versions := map[int]string{}
+
+	for _, file := range files {
+		matches := fileNamePattern.FindStringSubmatch(file.Name())
+		if file.IsDir() || matches == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, errors.New(ctx, err, opName, infra.KindBadRequest)
+		}
+
+		if duplicated, ok := versions[version]; ok {
+			return nil, errors.New(ctx, opName, infra.KindBadRequest, fmt.Sprintf("Duplicated migration version %d.", version), infra.Metadata{
+				"files": []string{duplicated, file.Name()},
+			})
+		}
+
+		versions[version] = file.Name()
+
+		content, err := ioutil.ReadFile(filepath.Join(c.in.Dir, file.Name()))
+		if err != nil {
+			return nil, errors.New(ctx, err, opName, infra.KindUnexpected)
+		}
+
+		migration := Migration{
+			Version: version,
+			Name:    matches[2],
+			Up:      string(content),
+		}
+
+		if index := strings.Index(migration.Up, downMarker); index >= 0 {
+			migration.Down = strings.TrimSpace(migration.Up[index+len(downMarker):])
+			migration.Up = migration.Up[:index]
+		}
+
+		migration.Up = strings.TrimSpace(migration.Up)
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Status - Lists every known migration and whether it was already applied
+func (c Client) Status(ctx context.Context) ([]Status, *infra.Error) {
+	const opName infra.OpName = "migrations.Status"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	applied, err := c.applied(ctx)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	statuses := []Status{}
+
+	for _, migration := range migrations {
+		status := Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+
+		if appliedMigration, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = appliedMigration.AppliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending - Lists the migrations that were not applied yet
+func (c Client) Pending(ctx context.Context) ([]Migration, *infra.Error) {
+	const opName infra.OpName = "migrations.Pending"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	applied, err := c.applied(ctx)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	pending := []Migration{}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Up - Applies every pending migration
+func (c Client) Up(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "migrations.Up"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	return c.To(ctx, migrations[len(migrations)-1].Version)
+}
+
+// Down - Reverts the latest applied migration
+func (c Client) Down(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "migrations.Down"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	applied, err := c.applied(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			return c.revert(ctx, migrations[i])
+		}
+	}
+
+	c.in.Log.Info(ctx, opName, "There is no migration to revert.")
+
+	return nil
+}
+
+// To - Applies or reverts migrations until the given version is the latest one applied
+func (c Client) To(ctx context.Context, version int) *infra.Error {
+	const opName infra.OpName = "migrations.To"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	if !hasVersion(migrations, version) {
+		return errors.New(ctx, opName, infra.KindNotFound, fmt.Sprintf("The migration %d was not found.", version))
+	}
+
+	applied, err := c.applied(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+
+		if err := c.revert(ctx, migration); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+	}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+
+		if err := c.apply(ctx, migration); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+	}
+
+	return nil
+}
+
+// Baseline - Marks every migration up to the given version as applied without running it.
+// Meant for databases whose schema was created by hand before the migrations were tracked.
+func (c Client) Baseline(ctx context.Context, version int) *infra.Error {
+	const opName infra.OpName = "migrations.Baseline"
+
+	migrations, err := c.Load(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	if !hasVersion(migrations, version) {
+		return errors.New(ctx, opName, infra.KindNotFound, fmt.Sprintf("The migration %d was not found.", version))
+	}
+
+	applied, err := c.applied(ctx)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	query := `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+
+		c.in.Log.InfoMetadata(ctx, opName, "Marking migration as applied...", infra.Metadata{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+
+		if _, err := c.in.Db.Execute(ctx, query, migration.Version, migration.Name); err != nil {
+			return errors.New(ctx, opName, err)
+		}
+	}
+
+	return nil
+}
+
+func (c Client) apply(ctx context.Context, migration Migration) *infra.Error {
+	const opName infra.OpName = "migrations.apply"
+
+	c.in.Log.InfoMetadata(ctx, opName, "Applying migration...", infra.Metadata{
+		"version": migration.Version,
+		"name":    migration.Name,
+	})
+
+	err := c.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := tx.Execute(ctx, migration.Up); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+
+		_, err := tx.Execute(ctx, query, migration.Version, migration.Name)
+		return err
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err, infra.Metadata{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+	}
+
+	return nil
+}
+
+func (c Client) revert(ctx context.Context, migration Migration) *infra.Error {
+	const opName infra.OpName = "migrations.revert"
+
+	if migration.Down == "" {
+		return errors.New(ctx, opName, infra.KindBadRequest, "The migration has no down section.", infra.Metadata{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+	}
+
+	c.in.Log.InfoMetadata(ctx, opName, "Reverting migration...", infra.Metadata{
+		"version": migration.Version,
+		"name":    migration.Name,
+	})
+
+	err := c.in.Db.WithTx(ctx, func(tx infra.RelationalDatabaseProvider) *infra.Error {
+		if _, err := tx.Execute(ctx, migration.Down); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM schema_migrations WHERE version = $1`
+
+		_, err := tx.Execute(ctx, query, migration.Version)
+		return err
+	})
+
+	if err != nil {
+		return errors.New(ctx, opName, err, infra.Metadata{
+			"version": migration.Version,
+			"name":    migration.Name,
+		})
+	}
+
+	return nil
+}
+
+func (c Client) applied(ctx context.Context) (map[int]appliedMigration, *infra.Error) {
+	const opName infra.OpName = "migrations.applied"
+
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version integer PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamp without time zone NOT NULL DEFAULT now()
+		)
+	`
+
+	if _, err := c.in.Db.Execute(ctx, query); err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	query = `SELECT version, name, applied_at::text as appliedAt FROM schema_migrations ORDER BY version`
+
+	cursor, err := c.in.Db.QueryAll(ctx, query)
+	if err != nil {
+		return nil, errors.New(ctx, opName, err)
+	}
+
+	defer cursor.Close(ctx)
+
+	applied := map[int]appliedMigration{}
+
+	for cursor.Next(ctx) {
+		migration := appliedMigration{}
+		if err := cursor.Decode(ctx, &migration); err != nil {
+			return nil, errors.New(ctx, opName, err)
+		}
+
+		applied[migration.Version] = migration
+	}
+
+	return applied, nil
+}
+
+func hasVersion(migrations []Migration, version int) bool {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
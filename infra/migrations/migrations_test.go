@@ -0,0 +1,332 @@
+package migrations
+
+import (
+	"context"
+	"database/sql/driver"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+	"github.com/lucasmls/backend-cacautime/infra/log"
+)
+
+// fakeDb - Keeps the schema_migrations rows and the statements run in memory. A transaction works on a copy
+// that only replaces the state when it succeeds. The statement equal to failOn runs, then fails, so only
+// rolling back undoes it.
+type fakeDb struct {
+	state  *fakeState
+	failOn string
+}
+
+type fakeState struct {
+	versions   map[int]string
+	statements []string
+}
+
+func newFakeDb() *fakeDb {
+	return &fakeDb{state: &fakeState{versions: map[int]string{}}}
+}
+
+func (s fakeState) copy() *fakeState {
+	versions := map[int]string{}
+	for version, name := range s.versions {
+		versions[version] = name
+	}
+
+	return &fakeState{versions: versions, statements: append([]string{}, s.statements...)}
+}
+
+func (db *fakeDb) Query(ctx context.Context, query string, args ...interface{}) infra.Decoder {
+	return &fakeCursor{}
+}
+
+func (db *fakeDb) QueryAll(ctx context.Context, query string, args ...interface{}) (infra.Cursor, *infra.Error) {
+	rows := []appliedMigration{}
+	for version, name := range db.state.versions {
+		rows = append(rows, appliedMigration{Version: version, Name: name})
+	}
+
+	return &fakeCursor{rows: rows, index: -1}, nil
+}
+
+func (db *fakeDb) Execute(ctx context.Context, query string, args ...interface{}) (driver.Result, *infra.Error) {
+	switch {
+	case strings.Contains(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		db.state.versions[args[0].(int)] = args[1].(string)
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		delete(db.state.versions, args[0].(int))
+	case query == db.failOn:
+		db.state.statements = append(db.state.statements, query)
+		return nil, errors.New(ctx, infra.OpName("fakeDb.Execute"), "The statement failed.", infra.KindUnexpected)
+	default:
+		db.state.statements = append(db.state.statements, query)
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (db *fakeDb) WithTx(ctx context.Context, fn func(infra.RelationalDatabaseProvider) *infra.Error, opts ...infra.TxOptions) *infra.Error {
+	tx := &fakeDb{state: db.state.copy(), failOn: db.failOn}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	db.state = tx.state
+
+	return nil
+}
+
+type fakeCursor struct {
+	rows  []appliedMigration
+	index int
+}
+
+func (c *fakeCursor) Next(ctx context.Context) bool {
+	c.index++
+	return c.index < len(c.rows)
+}
+
+func (c *fakeCursor) Decode(ctx context.Context, dest infra.Entity) *infra.Error {
+	if c.index < 0 || c.index >= len(c.rows) {
+		return errors.New(ctx, infra.OpName("fakeCursor.Decode"), "No rows.", infra.KindNotFound)
+	}
+
+	*dest.(*appliedMigration) = c.rows[c.index]
+	return nil
+}
+
+func (c *fakeCursor) Close(ctx context.Context) *infra.Error {
+	return nil
+}
+
+// newTestClient - A client over the files, written to a directory removed by the returned function
+func newTestClient(t *testing.T, files map[string]string) (*Client, *fakeDb, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup := func() { os.RemoveAll(dir) }
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+
+	logger, lErr := log.NewClient(log.ClientInput{Level: infra.SeverityCritical, GoEnv: infra.EnvironmentDevelop})
+	if lErr != nil {
+		cleanup()
+		t.Fatal(lErr)
+	}
+
+	db := newFakeDb()
+
+	client, cErr := NewClient(ClientInput{Log: logger, Db: db, Dir: dir})
+	if cErr != nil {
+		cleanup()
+		t.Fatal(cErr)
+	}
+
+	return client, db, cleanup
+}
+
+var testFiles = map[string]string{
+	"010-third.sql":  "CREATE third;\n\n-- migrate:down\nDROP third;\n",
+	"000-first.sql":  "CREATE first;\n\n-- migrate:down\nDROP first;\n",
+	"002-second.sql": "CREATE second;\n\n-- migrate:down\nDROP second;\n",
+	"README.md":      "not a migration",
+}
+
+func versionsOf(migrations []Migration) []int {
+	versions := []int{}
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+
+	return versions
+}
+
+func TestLoad(t *testing.T) {
+	client, _, cleanup := newTestClient(t, testFiles)
+	defer cleanup()
+
+	migrations, err := client.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := versionsOf(migrations), []int{0, 2, 10}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("versions = %v, want %v", got, want)
+	}
+
+	if got := migrations[1]; got.Name != "second" || got.Up != "CREATE second;" || got.Down != "DROP second;" {
+		t.Fatalf("migration = %+v", got)
+	}
+}
+
+func TestLoadDuplicatedVersion(t *testing.T) {
+	client, _, cleanup := newTestClient(t, map[string]string{
+		"001-one.sql":   "CREATE one;",
+		"001-other.sql": "CREATE other;",
+	})
+	defer cleanup()
+
+	if _, err := client.Load(context.Background()); err == nil || errors.Kind(err) != infra.KindBadRequest {
+		t.Fatalf("err = %v, want a bad request", err)
+	}
+}
+
+func TestUpAndPending(t *testing.T) {
+	ctx := context.Background()
+	client, db, cleanup := newTestClient(t, testFiles)
+	defer cleanup()
+
+	pending, err := client.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := versionsOf(pending), []int{0, 2, 10}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pending = %v, want %v", got, want)
+	}
+
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := db.state.statements, []string{"CREATE first;", "CREATE second;", "CREATE third;"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %v, want %v", got, want)
+	}
+
+	pending, err = client.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("pending = %v, want none", versionsOf(pending))
+	}
+
+	// Nothing is left to apply
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.state.statements) != 3 {
+		t.Fatalf("statements = %v, want them run once", db.state.statements)
+	}
+}
+
+func TestDown(t *testing.T) {
+	ctx := context.Background()
+	client, db, cleanup := newTestClient(t, testFiles)
+	defer cleanup()
+
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := db.state.statements[len(db.state.statements)-1]; got != "DROP third;" {
+		t.Fatalf("last statement = %q, want the latest migration reverted", got)
+	}
+
+	if _, ok := db.state.versions[10]; ok {
+		t.Fatal("the reverted migration is still marked as applied")
+	}
+
+	if len(db.state.versions) != 2 {
+		t.Fatalf("applied = %v, want the first two", db.state.versions)
+	}
+}
+
+func TestDownWithoutDownSection(t *testing.T) {
+	ctx := context.Background()
+	client, db, cleanup := newTestClient(t, map[string]string{"001-one.sql": "CREATE one;"})
+	defer cleanup()
+
+	if err := client.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Down(ctx); err == nil || errors.Kind(err) != infra.KindBadRequest {
+		t.Fatalf("err = %v, want a bad request", err)
+	}
+
+	if _, ok := db.state.versions[1]; !ok {
+		t.Fatal("the migration was unmarked without being reverted")
+	}
+}
+
+func TestTo(t *testing.T) {
+	ctx := context.Background()
+	client, db, cleanup := newTestClient(t, testFiles)
+	defer cleanup()
+
+	if err := client.To(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := db.state.statements, []string{"CREATE first;", "CREATE second;"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %v, want %v", got, want)
+	}
+
+	if err := client.To(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"CREATE first;", "CREATE second;", "CREATE third;", "DROP third;", "DROP second;"}
+	if got := db.state.statements; !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %v, want %v", got, want)
+	}
+
+	if err := client.To(ctx, 5); err == nil || errors.Kind(err) != infra.KindNotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestFailedApplyRollsBack(t *testing.T) {
+	ctx := context.Background()
+	client, db, cleanup := newTestClient(t, testFiles)
+	defer cleanup()
+	db.failOn = "CREATE second;"
+
+	if err := client.Up(ctx); err == nil {
+		t.Fatal("err = nil, want the failed migration")
+	}
+
+	if got, want := db.state.statements, []string{"CREATE first;"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %v, want %v", got, want)
+	}
+
+	if _, ok := db.state.versions[2]; ok {
+		t.Fatal("the failed migration is marked as applied")
+	}
+
+	pending, err := client.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := versionsOf(pending), []int{2, 10}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pending = %v, want %v", got, want)
+	}
+}
@@ -23,7 +23,7 @@ func (d decoder) Decode(ctx context.Context, dest infra.Entity) *infra.Error {
 	}
 
 	if err != nil {
-		return errors.New(ctx, opName, err)
+		return errors.New(ctx, opName, err, errorKind(err))
 	}
 
 	return nil
@@ -2,14 +2,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
+	"fmt"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lucasmls/backend-cacautime/infra"
 	"github.com/lucasmls/backend-cacautime/infra/errors"
 
-	// Needed only to enable postgres driver
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // ClientInput ...
@@ -17,12 +18,22 @@ type ClientInput struct {
 	Log                infra.LogProvider
 	ConnectionString   string
 	MaxConnectionsOpen int
+	TxIsolationLevel   infra.TxIsolationLevel
+	TxMaxRetries       int
+}
+
+// executor is implemented by both *sqlx.DB and *sqlx.Tx
+type executor interface {
+	QueryRowxContext(context.Context, string, ...interface{}) *sqlx.Row
+	QueryxContext(context.Context, string, ...interface{}) (*sqlx.Rows, error)
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
 }
 
 // Client ...
 type Client struct {
 	in ClientInput
 	db *sqlx.DB
+	tx *sqlx.Tx
 }
 
 // NewClient ...
@@ -55,12 +66,35 @@ func NewClient(in ClientInput) (*Client, *infra.Error) {
 
 	db.SetMaxOpenConns(in.MaxConnectionsOpen)
 
+	if in.TxIsolationLevel == "" {
+		in.TxIsolationLevel = infra.TxReadCommitted
+	}
+
+	if _, ok := isolationLevels[in.TxIsolationLevel]; !ok {
+		err := fmt.Errorf("unknown transaction isolation level: %s", in.TxIsolationLevel)
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.TxMaxRetries < 0 {
+		err := infra.MinimumValueError{EnvVarName: "TxMaxRetries", MinimumRequired: 0}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
 	return &Client{
 		in: in,
 		db: db,
 	}, nil
 }
 
+// executor - Returns the ongoing transaction, if any, or the connection pool
+func (c Client) executor() executor {
+	if c.tx != nil {
+		return c.tx
+	}
+
+	return c.db
+}
+
 // Query - Executes a query that return only one row
 func (c Client) Query(ctx context.Context, query string, args ...interface{}) infra.Decoder {
 	const opName infra.OpName = "postgres.Query"
@@ -70,7 +104,7 @@ func (c Client) Query(ctx context.Context, query string, args ...interface{}) in
 		"args":  args,
 	})
 
-	row := c.db.QueryRowxContext(ctx, query, args...)
+	row := c.executor().QueryRowxContext(ctx, query, args...)
 
 	return decoder{row: row}
 }
@@ -84,9 +118,9 @@ func (c Client) QueryAll(ctx context.Context, query string, args ...interface{})
 		"args":  args,
 	})
 
-	rows, err := c.db.QueryxContext(ctx, query, args...)
+	rows, err := c.executor().QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, errors.New(ctx, err, opName)
+		return nil, errors.New(ctx, err, opName, errorKind(err))
 	}
 
 	return cursor{rows: rows}, nil
@@ -96,9 +130,9 @@ func (c Client) QueryAll(ctx context.Context, query string, args ...interface{})
 func (c Client) Execute(ctx context.Context, query string, args ...interface{}) (driver.Result, *infra.Error) {
 	const opName infra.OpName = "postgres.Execute"
 
-	result, err := c.db.ExecContext(ctx, query, args...)
+	result, err := c.executor().ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, errors.New(ctx, err, opName, infra.Metadata{
+		return nil, errors.New(ctx, err, opName, errorKind(err), infra.Metadata{
 			"query": query,
 			"args":  args,
 		})
@@ -106,3 +140,30 @@ func (c Client) Execute(ctx context.Context, query string, args ...interface{})
 
 	return result, nil
 }
+
+// Close - Closes the connection pool, waiting for the queries in progress to finish
+func (c Client) Close(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "postgres.Close"
+
+	c.in.Log.Info(ctx, opName, "Closing the connection pool...")
+
+	if err := c.db.Close(); err != nil {
+		return errors.New(ctx, err, opName, infra.KindUnexpected)
+	}
+
+	return nil
+}
+
+// errorKind - Classifies integrity constraint violations, which are caused by the data sent and not by the server
+func errorKind(err error) infra.ErrorKind {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code.Class() != "23" {
+		return 0
+	}
+
+	if pqErr.Code.Name() == "unique_violation" {
+		return infra.KindConflict
+	}
+
+	return infra.KindBadRequest
+}
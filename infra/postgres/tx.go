@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+var isolationLevels = map[infra.TxIsolationLevel]sql.IsolationLevel{
+	infra.TxReadCommitted:  sql.LevelReadCommitted,
+	infra.TxRepeatableRead: sql.LevelRepeatableRead,
+	infra.TxSerializable:   sql.LevelSerializable,
+}
+
+// retryableErrorCodes are the postgres errors that are solved by running the transaction again
+var retryableErrorCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+const retryBackoff = 20 * time.Millisecond
+
+// WithTx - Runs fn inside a transaction, committing it when fn succeeds and rolling it back otherwise.
+// Transactions aborted by serialization failures or deadlocks are retried from scratch, so fn must be safe to run more than once.
+// When called from a transaction provider, fn joins the ongoing transaction and the options are ignored.
+func (c Client) WithTx(ctx context.Context, fn func(infra.RelationalDatabaseProvider) *infra.Error, opts ...infra.TxOptions) *infra.Error {
+	const opName infra.OpName = "postgres.WithTx"
+
+	if c.tx != nil {
+		return fn(c)
+	}
+
+	options := infra.TxOptions{
+		Isolation:  c.in.TxIsolationLevel,
+		MaxRetries: c.in.TxMaxRetries,
+	}
+
+	for _, opt := range opts {
+		if opt.Isolation != "" {
+			options.Isolation = opt.Isolation
+		}
+
+		if opt.MaxRetries > 0 {
+			options.MaxRetries = opt.MaxRetries
+		}
+
+		options.ReadOnly = opt.ReadOnly
+	}
+
+	isolation, ok := isolationLevels[options.Isolation]
+	if !ok {
+		return errors.New(ctx, opName, infra.KindBadRequest, "Unknown transaction isolation level.", infra.Metadata{
+			"isolation": options.Isolation,
+		})
+	}
+
+	txOptions := &sql.TxOptions{
+		Isolation: isolation,
+		ReadOnly:  options.ReadOnly,
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.runTx(ctx, txOptions, fn)
+		if err == nil {
+			return nil
+		}
+
+		if !isRetryable(err) || attempt >= options.MaxRetries {
+			return errors.New(ctx, opName, err)
+		}
+
+		c.in.Log.WarningMetadata(ctx, opName, "Retrying the transaction...", infra.Metadata{
+			"attempt": attempt + 1,
+			"error":   errors.Error(err).Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return errors.New(ctx, opName, ctx.Err())
+		case <-time.After(time.Duration(attempt+1) * retryBackoff):
+		}
+	}
+}
+
+func (c Client) runTx(ctx context.Context, opts *sql.TxOptions, fn func(infra.RelationalDatabaseProvider) *infra.Error) *infra.Error {
+	const opName infra.OpName = "postgres.runTx"
+
+	c.in.Log.Debug(ctx, opName, "Starting transaction...")
+
+	tx, err := c.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(Client{in: c.in, db: c.db, tx: tx}); err != nil {
+		if rErr := tx.Rollback(); rErr != nil {
+			c.in.Log.WarningMetadata(ctx, opName, "Failed to rollback the transaction.", infra.Metadata{
+				"error": rErr.Error(),
+			})
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.New(ctx, opName, err)
+	}
+
+	return nil
+}
+
+func isRetryable(err *infra.Error) bool {
+	pqErr, ok := errors.Error(err).(*pq.Error)
+	if !ok {
+		return false
+	}
+
+	return retryableErrorCodes[pqErr.Code]
+}
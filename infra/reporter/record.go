@@ -0,0 +1,38 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// record is the serialized shape of an error sent to the file and HTTP sinks
+type record struct {
+	Time      string          `json:"time"`
+	ContextID interface{}     `json:"contextID,omitempty"`
+	Severity  infra.Severity  `json:"severity"`
+	Kind      infra.ErrorKind `json:"kind"`
+	OpName    infra.OpName    `json:"opName"`
+	Message   string          `json:"message"`
+	Trace     []infra.OpName  `json:"trace"`
+	Metadata  infra.Metadata  `json:"metadata,omitempty"`
+}
+
+func newRecord(err *infra.Error) record {
+	message := ""
+	if rootErr := errors.Error(err); rootErr != nil {
+		message = rootErr.Error()
+	}
+
+	return record{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		ContextID: errors.Context(err).Value(infra.IDContextValueKey),
+		Severity:  errors.Severity(err),
+		Kind:      errors.Kind(err),
+		OpName:    errors.OpName(err),
+		Message:   message,
+		Trace:     errors.Trace(err),
+		Metadata:  errors.Metadata(err),
+	}
+}
@@ -0,0 +1,184 @@
+package reporter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// Stats ...
+type Stats struct {
+	Reported  uint64 `json:"reported"`
+	Dropped   uint64 `json:"dropped"`
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+}
+
+// ClientInput ...
+type ClientInput struct {
+	Log         infra.LogProvider
+	Sinks       []infra.ErrorSink
+	QueueSize   int
+	SendTimeout time.Duration
+}
+
+// Client - Delivers the reported errors to every sink in background.
+// Reporting never blocks: when the queue is full the error is dropped and counted.
+type Client struct {
+	// Counters come first to keep them 64-bit aligned for the atomic operations
+	reported  uint64
+	dropped   uint64
+	delivered uint64
+	failed    uint64
+
+	in    ClientInput
+	queue chan *infra.Error
+	done  chan struct{}
+
+	mutex  sync.RWMutex
+	closed bool
+}
+
+// NewClient ...
+func NewClient(in ClientInput) (*Client, *infra.Error) {
+	const opName infra.OpName = "reporter.NewClient"
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if len(in.Sinks) == 0 {
+		err := infra.MissingDependencyError{DependencyName: "Sinks"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.QueueSize < 1 {
+		err := infra.MinimumValueError{EnvVarName: "QueueSize", MinimumRequired: 1}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	if in.SendTimeout <= 0 {
+		in.SendTimeout = 5 * time.Second
+	}
+
+	c := &Client{
+		in:    in,
+		queue: make(chan *infra.Error, in.QueueSize),
+		done:  make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c, nil
+}
+
+// Report - Enqueues the error to be delivered to the sinks
+func (c *Client) Report(err *infra.Error) {
+	if err == nil {
+		return
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.closed {
+		atomic.AddUint64(&c.dropped, 1)
+		return
+	}
+
+	select {
+	case c.queue <- err:
+		atomic.AddUint64(&c.reported, 1)
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// Stats ...
+func (c *Client) Stats() Stats {
+	return Stats{
+		Reported:  atomic.LoadUint64(&c.reported),
+		Dropped:   atomic.LoadUint64(&c.dropped),
+		Delivered: atomic.LoadUint64(&c.delivered),
+		Failed:    atomic.LoadUint64(&c.failed),
+	}
+}
+
+// Close - Stops accepting errors and waits until the pending ones are delivered or ctx is done, then closes the sinks
+func (c *Client) Close(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "reporter.Close"
+
+	c.mutex.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		c.in.Log.WarningMetadata(ctx, opName, "Gave up flushing the pending errors.", infra.Metadata{
+			"pending": len(c.queue),
+		})
+	}
+
+	for _, sink := range c.in.Sinks {
+		if err := sink.Close(ctx); err != nil {
+			errors.Log(c.in.Log, errors.New(ctx, opName, err))
+		}
+	}
+
+	c.in.Log.InfoMetadata(ctx, opName, "Error reporter closed.", infra.Metadata{
+		"stats": c.Stats(),
+	})
+
+	return nil
+}
+
+func (c *Client) run() {
+	const opName infra.OpName = "reporter.run"
+
+	defer close(c.done)
+
+	var droppedWarned uint64
+
+	for err := range c.queue {
+		c.deliver(err)
+
+		if dropped := atomic.LoadUint64(&c.dropped); dropped > droppedWarned {
+			c.in.Log.WarningMetadata(context.Background(), opName, "The error queue overflowed, some errors were dropped.", infra.Metadata{
+				"dropped":      dropped - droppedWarned,
+				"totalDropped": dropped,
+				"queueSize":    c.in.QueueSize,
+			})
+
+			droppedWarned = dropped
+		}
+	}
+}
+
+func (c *Client) deliver(err *infra.Error) {
+	const opName infra.OpName = "reporter.deliver"
+
+	for _, sink := range c.in.Sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), c.in.SendTimeout)
+
+		if sErr := sink.Send(ctx, err); sErr != nil {
+			atomic.AddUint64(&c.failed, 1)
+			c.in.Log.WarningMetadata(ctx, opName, "Failed to deliver an error to a sink.", infra.Metadata{
+				"error": errors.Error(sErr).Error(),
+				"trace": errors.Trace(sErr),
+			})
+		} else {
+			atomic.AddUint64(&c.delivered, 1)
+		}
+
+		cancel()
+	}
+}
@@ -0,0 +1,184 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/lucasmls/backend-cacautime/infra"
+	"github.com/lucasmls/backend-cacautime/infra/errors"
+)
+
+// LogSinkInput ...
+type LogSinkInput struct {
+	Log infra.LogProvider
+}
+
+// LogSink - Writes the errors through the log provider
+type LogSink struct {
+	in LogSinkInput
+}
+
+// NewLogSink ...
+func NewLogSink(in LogSinkInput) (*LogSink, *infra.Error) {
+	const opName infra.OpName = "reporter.NewLogSink"
+
+	if in.Log == nil {
+		err := infra.MissingDependencyError{DependencyName: "Log"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &LogSink{
+		in: in,
+	}, nil
+}
+
+// Send ...
+func (s LogSink) Send(ctx context.Context, err *infra.Error) *infra.Error {
+	errors.Log(s.in.Log, err)
+	return nil
+}
+
+// Close ...
+func (s LogSink) Close(ctx context.Context) *infra.Error {
+	return nil
+}
+
+// FileSinkInput ...
+type FileSinkInput struct {
+	Path string
+}
+
+// FileSink - Appends the errors to a file, one JSON document per line
+type FileSink struct {
+	in    FileSinkInput
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileSink ...
+func NewFileSink(in FileSinkInput) (*FileSink, *infra.Error) {
+	const opName infra.OpName = "reporter.NewFileSink"
+
+	if in.Path == "" {
+		err := infra.MissingDependencyError{DependencyName: "Path"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	file, err := os.OpenFile(in.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &FileSink{
+		in:   in,
+		file: file,
+	}, nil
+}
+
+// Send ...
+func (s *FileSink) Send(ctx context.Context, err *infra.Error) *infra.Error {
+	const opName infra.OpName = "reporter.FileSink.Send"
+
+	line, mErr := json.Marshal(newRecord(err))
+	if mErr != nil {
+		return errors.New(ctx, mErr, opName)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, wErr := s.file.Write(append(line, '\n')); wErr != nil {
+		return errors.New(ctx, wErr, opName, infra.Metadata{
+			"path": s.in.Path,
+		})
+	}
+
+	return nil
+}
+
+// Close ...
+func (s *FileSink) Close(ctx context.Context) *infra.Error {
+	const opName infra.OpName = "reporter.FileSink.Close"
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.file.Close(); err != nil {
+		return errors.New(ctx, err, opName, infra.Metadata{
+			"path": s.in.Path,
+		})
+	}
+
+	return nil
+}
+
+// HTTPSinkInput ...
+type HTTPSinkInput struct {
+	URL string
+}
+
+// HTTPSink - Posts the errors as JSON to a collector
+type HTTPSink struct {
+	in     HTTPSinkInput
+	client *http.Client
+}
+
+// NewHTTPSink ...
+func NewHTTPSink(in HTTPSinkInput) (*HTTPSink, *infra.Error) {
+	const opName infra.OpName = "reporter.NewHTTPSink"
+
+	if in.URL == "" {
+		err := infra.MissingDependencyError{DependencyName: "URL"}
+		return nil, errors.New(err, opName, infra.KindBadRequest)
+	}
+
+	return &HTTPSink{
+		in:     in,
+		client: &http.Client{},
+	}, nil
+}
+
+// Send ...
+func (s HTTPSink) Send(ctx context.Context, err *infra.Error) *infra.Error {
+	const opName infra.OpName = "reporter.HTTPSink.Send"
+
+	body, mErr := json.Marshal(newRecord(err))
+	if mErr != nil {
+		return errors.New(ctx, mErr, opName)
+	}
+
+	request, rErr := http.NewRequest(http.MethodPost, s.in.URL, bytes.NewReader(body))
+	if rErr != nil {
+		return errors.New(ctx, rErr, opName)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, rErr := s.client.Do(request.WithContext(ctx))
+	if rErr != nil {
+		return errors.New(ctx, rErr, opName, infra.Metadata{
+			"url": s.in.URL,
+		})
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return errors.New(ctx, fmt.Sprintf("The collector answered with status %d.", response.StatusCode), opName, infra.Metadata{
+			"url": s.in.URL,
+		})
+	}
+
+	return nil
+}
+
+// Close ...
+func (s HTTPSink) Close(ctx context.Context) *infra.Error {
+	s.client.CloseIdleConnections()
+	return nil
+}
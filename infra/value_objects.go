@@ -42,6 +42,25 @@ const (
 	IDContextValueKey string = "contextID"
 )
 
+// TxIsolationLevel ...
+type TxIsolationLevel string
+
+const (
+	// TxReadCommitted ...
+	TxReadCommitted TxIsolationLevel = "read_committed"
+	// TxRepeatableRead ...
+	TxRepeatableRead TxIsolationLevel = "repeatable_read"
+	// TxSerializable ...
+	TxSerializable TxIsolationLevel = "serializable"
+)
+
+// TxOptions - Overrides the provider defaults for a single transaction
+type TxOptions struct {
+	Isolation  TxIsolationLevel
+	MaxRetries int
+	ReadOnly   bool
+}
+
 // ErrorKind ...
 type ErrorKind int
 
@@ -50,8 +69,14 @@ const (
 	KindBadRequest ErrorKind = http.StatusBadRequest
 	// KindUnauthorized ...
 	KindUnauthorized ErrorKind = http.StatusUnauthorized
+	// KindForbidden ...
+	KindForbidden ErrorKind = http.StatusForbidden
 	// KindNotFound ...
 	KindNotFound ErrorKind = http.StatusNotFound
+	// KindConflict ...
+	KindConflict ErrorKind = http.StatusConflict
+	// KindTooManyRequests ...
+	KindTooManyRequests ErrorKind = http.StatusTooManyRequests
 	// KindUnexpected ...
 	KindUnexpected ErrorKind = http.StatusInternalServerError
 	// KindExpected ...
@@ -117,8 +142,15 @@ func (e MinimumValueError) Error() string {
 	return fmt.Sprintf("missing value: %s - minimum required: %d", e.EnvVarName, e.MinimumRequired)
 }
 
+// TokenClaims - What the token tells about its bearer
+type TokenClaims struct {
+	UserID    string `json:"userId"`
+	Role      string `json:"role"`
+	SessionID string `json:"sessionId"`
+}
+
 // DecodedJWT ...
 type DecodedJWT struct {
-	UserID string `json:"userId"`
-	Exp    int64  `json:"exp"`
+	TokenClaims
+	Exp int64 `json:"exp"`
 }
@@ -8,5 +8,15 @@ until psql -v ON_ERROR_STOP=1 -Atx "$POSTGRES_CONNECTION_STRING"; do
   sleep 2
 done
 
+# A schema created by hand, before the migrations were tracked, already has what 000-009 create
+schema_untracked=$(psql -Atc "SELECT to_regclass('public.customers') IS NOT NULL AND to_regclass('public.schema_migrations') IS NULL" "$POSTGRES_CONNECTION_STRING") || exit 1
+if [ "$schema_untracked" = "t" ]; then
+  printf "Marking the existing database schema as migrated up to 9...\n"
+  go run ./cmd/server migrate baseline 9 || exit 1
+fi
+
+printf "Applying database migrations...\n"
+go run ./cmd/server migrate up || exit 1
+
 printf "Starting application...\n"
-go run cmd/server/main.go
\ No newline at end of file
+go run ./cmd/server
\ No newline at end of file
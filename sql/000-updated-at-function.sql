@@ -4,4 +4,7 @@ BEGIN
   NEW.updated_at = NOW();
   RETURN NEW;
 END;
-$$ LANGUAGE plpgsql;
\ No newline at end of file
+$$ LANGUAGE plpgsql;
+
+-- migrate:down
+DROP FUNCTION IF EXISTS trigger_set_timestamp();
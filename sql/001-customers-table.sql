@@ -7,11 +7,11 @@ CREATE TABLE customers (
   updated_at timestamp without time zone NOT NULL DEFAULT now()
 );
 
--- Indices -------------------------------------------------------
-CREATE UNIQUE INDEX customers_pkey ON customers(id int4_ops);
-
 -- Triggers -------------------------------------------------------
 CREATE TRIGGER set_timestamp
 BEFORE UPDATE ON customers
 FOR EACH ROW
-EXECUTE PROCEDURE trigger_set_timestamp();
\ No newline at end of file
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS customers;
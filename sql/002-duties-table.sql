@@ -14,4 +14,7 @@ CREATE UNIQUE INDEX sales_day_pkey ON duties(id int4_ops);
 CREATE TRIGGER set_timestamp
 BEFORE UPDATE ON duties
 FOR EACH ROW
-EXECUTE PROCEDURE trigger_set_timestamp();
\ No newline at end of file
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS duties;
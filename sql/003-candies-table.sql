@@ -7,11 +7,11 @@ CREATE TABLE candies (
   updated_at timestamp without time zone NOT NULL DEFAULT now()
 );
 
--- Indices -------------------------------------------------------
-CREATE UNIQUE INDEX candies_pkey ON candies(id int4_ops);
-
 -- Triggers -------------------------------------------------------
 CREATE TRIGGER set_timestamp
 BEFORE UPDATE ON candies
 FOR EACH ROW
-EXECUTE PROCEDURE trigger_set_timestamp();
\ No newline at end of file
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS candies;
@@ -8,11 +8,11 @@ CREATE TABLE users (
   updated_at timestamp without time zone NOT NULL DEFAULT now()
 );
 
--- Indices -------------------------------------------------------
-CREATE UNIQUE INDEX users_pkey ON users(id int4_ops);
-
 -- Triggers -------------------------------------------------------
 CREATE TRIGGER set_timestamp
 BEFORE UPDATE ON users
 FOR EACH ROW
-EXECUTE PROCEDURE trigger_set_timestamp();
\ No newline at end of file
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS users;
@@ -1 +1,5 @@
 alter table customers alter column phone drop not null;
+
+-- migrate:down
+update customers set phone = '' where phone is null;
+alter table customers alter column phone set not null;
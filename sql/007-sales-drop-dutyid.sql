@@ -1 +1,4 @@
-alter table sales drop column duty_id;
\ No newline at end of file
+alter table sales drop column duty_id;
+
+-- migrate:down
+alter table sales add column duty_id integer constraint duty_fk references duties(id) on delete cascade on update cascade;
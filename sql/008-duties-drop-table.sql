@@ -1 +1,17 @@
-drop table if exists duties;
\ No newline at end of file
+drop table if exists duties;
+
+-- migrate:down
+CREATE TABLE duties (
+  id SERIAL PRIMARY KEY,
+  date date NOT NULL,
+  candy_quantity integer NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX sales_day_pkey ON duties(id int4_ops);
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON duties
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
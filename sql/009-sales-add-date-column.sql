@@ -1 +1,4 @@
-ALTER TABLE sales add COLUMN date date NOT NULL;
\ No newline at end of file
+ALTER TABLE sales add COLUMN date date NOT NULL;
+
+-- migrate:down
+ALTER TABLE sales drop COLUMN date;
@@ -0,0 +1,22 @@
+-- Columns -------------------------------------------------------
+ALTER TABLE sales ADD COLUMN candy_name text;
+ALTER TABLE sales ADD COLUMN candy_price integer;
+
+-- Backfill ------------------------------------------------------
+UPDATE sales s
+SET
+  candy_name = ca.name,
+  candy_price = ca.price
+FROM candies ca
+WHERE ca.id = s.candy_id;
+
+ALTER TABLE sales ALTER COLUMN candy_name SET NOT NULL;
+ALTER TABLE sales ALTER COLUMN candy_price SET NOT NULL;
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN sales.candy_name IS 'The candy name when the sale was registered';
+COMMENT ON COLUMN sales.candy_price IS 'The candy price when the sale was registered';
+
+-- migrate:down
+ALTER TABLE sales DROP COLUMN candy_price;
+ALTER TABLE sales DROP COLUMN candy_name;
@@ -0,0 +1,69 @@
+-- Orders ---------------------------------------------------------
+-- Every sale becomes an order, keeping its id, customer, status, payment method and date.
+ALTER TABLE sales RENAME TO orders;
+
+-- Table Definition ----------------------------------------------
+CREATE TABLE order_items (
+  id SERIAL PRIMARY KEY,
+  order_id integer NOT NULL CONSTRAINT order_fk REFERENCES orders(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  candy_id integer NOT NULL CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  candy_name text NOT NULL,
+  unit_price integer NOT NULL,
+  quantity integer NOT NULL DEFAULT 1,
+  discount integer NOT NULL DEFAULT 0,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT quantity_positive CHECK (quantity > 0),
+  CONSTRAINT discount_within_amount CHECK (discount >= 0 AND discount <= unit_price * quantity)
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN order_items.candy_name IS 'The candy name when the order was registered';
+COMMENT ON COLUMN order_items.unit_price IS 'The candy price when the order was registered';
+COMMENT ON COLUMN order_items.discount IS 'Discount applied to the whole line';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX order_items_order_id_idx ON order_items(order_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON order_items
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Backfill ------------------------------------------------------
+INSERT INTO order_items (order_id, candy_id, candy_name, unit_price, quantity, discount, created_at, updated_at)
+SELECT id, candy_id, candy_name, candy_price, 1, 0, created_at, updated_at
+FROM orders;
+
+ALTER TABLE orders DROP COLUMN candy_id;
+ALTER TABLE orders DROP COLUMN candy_name;
+ALTER TABLE orders DROP COLUMN candy_price;
+
+-- migrate:down
+-- Only the first line of each order survives, and quantities and discounts are lost.
+ALTER TABLE orders ADD COLUMN candy_id integer CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE orders ADD COLUMN candy_name text;
+ALTER TABLE orders ADD COLUMN candy_price integer;
+
+UPDATE orders o
+SET
+  candy_id = i.candy_id,
+  candy_name = i.candy_name,
+  candy_price = i.unit_price
+FROM (
+  SELECT DISTINCT ON (order_id) order_id, candy_id, candy_name, unit_price
+  FROM order_items
+  ORDER BY order_id, id
+) i
+WHERE i.order_id = o.id;
+
+DELETE FROM orders WHERE candy_id IS NULL;
+
+ALTER TABLE orders ALTER COLUMN candy_id SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN candy_name SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN candy_price SET NOT NULL;
+
+DROP TABLE order_items;
+
+ALTER TABLE orders RENAME TO sales;
@@ -0,0 +1,75 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE payments (
+  id SERIAL PRIMARY KEY,
+  customer_id integer NOT NULL CONSTRAINT customer_fk REFERENCES customers(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  amount integer NOT NULL,
+  payment_method text NOT NULL,
+  date date NOT NULL,
+  note text,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT amount_positive CHECK (amount > 0)
+);
+
+CREATE TABLE payment_allocations (
+  id SERIAL PRIMARY KEY,
+  payment_id integer NOT NULL CONSTRAINT payment_fk REFERENCES payments(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  order_id integer NOT NULL CONSTRAINT order_fk REFERENCES orders(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  amount integer NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT amount_positive CHECK (amount > 0)
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN payments.payment_method IS 'money/transfer';
+COMMENT ON TABLE payment_allocations IS 'How much of each payment settled each order';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX payments_customer_id_idx ON payments(customer_id int4_ops);
+CREATE INDEX payment_allocations_payment_id_idx ON payment_allocations(payment_id int4_ops);
+CREATE INDEX payment_allocations_order_id_idx ON payment_allocations(order_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON payments
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON payment_allocations
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- Views ---------------------------------------------------------
+-- Orders registered as paid are settled on the spot, the others owe their total minus what was allocated to them.
+CREATE VIEW order_balances AS
+SELECT
+  o.id AS order_id,
+  o.customer_id,
+  o.status,
+  o.payment_method,
+  o.date,
+  t.total,
+  COALESCE(a.allocated, 0) AS allocated,
+  CASE
+    WHEN o.status = 'paid' THEN 0
+    ELSE GREATEST(t.total - COALESCE(a.allocated, 0), 0)
+  END AS outstanding
+FROM
+  orders o
+  INNER JOIN (
+    SELECT order_id, SUM(unit_price * quantity - discount) AS total
+    FROM order_items
+    GROUP BY order_id
+  ) t ON t.order_id = o.id
+  LEFT JOIN (
+    SELECT order_id, SUM(amount) AS allocated
+    FROM payment_allocations
+    GROUP BY order_id
+  ) a ON a.order_id = o.id;
+
+-- migrate:down
+DROP VIEW IF EXISTS order_balances;
+DROP TABLE IF EXISTS payment_allocations;
+DROP TABLE IF EXISTS payments;
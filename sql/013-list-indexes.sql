@@ -0,0 +1,10 @@
+-- Indices -------------------------------------------------------
+-- Back the default sort of the paginated lists, with the id breaking the ties
+CREATE INDEX customers_name_id_idx ON customers(name, id);
+CREATE INDEX candies_name_id_idx ON candies(name, id);
+CREATE INDEX orders_date_id_idx ON orders(date, id);
+
+-- migrate:down
+DROP INDEX IF EXISTS orders_date_id_idx;
+DROP INDEX IF EXISTS candies_name_id_idx;
+DROP INDEX IF EXISTS customers_name_id_idx;
@@ -0,0 +1,41 @@
+-- Stock ----------------------------------------------------------
+-- The current stock is kept on the candy and changed in the same statement that records the movement,
+-- so concurrent sales of the same candy are serialized by its row lock.
+ALTER TABLE candies ADD COLUMN stock integer NOT NULL DEFAULT 0;
+ALTER TABLE candies ADD COLUMN low_stock_threshold integer NOT NULL DEFAULT 5;
+ALTER TABLE candies ADD CONSTRAINT low_stock_threshold_not_negative CHECK (low_stock_threshold >= 0);
+
+-- Table Definition ----------------------------------------------
+CREATE TABLE stock_movements (
+  id SERIAL PRIMARY KEY,
+  candy_id integer NOT NULL CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  kind text NOT NULL,
+  quantity integer NOT NULL,
+  order_id integer CONSTRAINT order_fk REFERENCES orders(id) ON DELETE SET NULL ON UPDATE CASCADE,
+  note text,
+  date date NOT NULL DEFAULT CURRENT_DATE,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT quantity_not_zero CHECK (quantity <> 0),
+  CONSTRAINT kind_known CHECK (kind IN ('production', 'sale', 'waste', 'adjustment'))
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN stock_movements.kind IS 'production/sale/waste/adjustment';
+COMMENT ON COLUMN stock_movements.quantity IS 'Positive when it adds to the stock, negative otherwise';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX stock_movements_candy_id_idx ON stock_movements(candy_id int4_ops);
+CREATE INDEX stock_movements_order_id_idx ON stock_movements(order_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON stock_movements
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS stock_movements;
+ALTER TABLE candies DROP CONSTRAINT IF EXISTS low_stock_threshold_not_negative;
+ALTER TABLE candies DROP COLUMN IF EXISTS low_stock_threshold;
+ALTER TABLE candies DROP COLUMN IF EXISTS stock;
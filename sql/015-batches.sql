@@ -0,0 +1,51 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE batches (
+  id SERIAL PRIMARY KEY,
+  candy_id integer NOT NULL CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  produced integer NOT NULL,
+  remaining integer NOT NULL,
+  written_off integer NOT NULL DEFAULT 0,
+  production_date date NOT NULL,
+  expiry_date date NOT NULL,
+  written_off_at date,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT produced_positive CHECK (produced > 0),
+  CONSTRAINT remaining_within_produced CHECK (remaining >= 0 AND remaining + written_off <= produced),
+  CONSTRAINT expiry_after_production CHECK (expiry_date >= production_date)
+);
+
+CREATE TABLE batch_consumptions (
+  id SERIAL PRIMARY KEY,
+  batch_id integer NOT NULL CONSTRAINT batch_fk REFERENCES batches(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  order_item_id integer NOT NULL CONSTRAINT order_item_fk REFERENCES order_items(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  quantity integer NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT quantity_positive CHECK (quantity > 0)
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN batches.remaining IS 'Units not sold nor written off yet';
+COMMENT ON COLUMN batches.written_off IS 'Units thrown away because the batch expired';
+COMMENT ON TABLE batch_consumptions IS 'Which batches each order item was taken from';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX batches_candy_id_expiry_date_idx ON batches(candy_id, expiry_date);
+CREATE INDEX batch_consumptions_batch_id_idx ON batch_consumptions(batch_id int4_ops);
+CREATE INDEX batch_consumptions_order_item_id_idx ON batch_consumptions(order_item_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON batches
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON batch_consumptions
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS batch_consumptions;
+DROP TABLE IF EXISTS batches;
@@ -0,0 +1,40 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE duties (
+  id SERIAL PRIMARY KEY,
+  opened_at timestamp without time zone NOT NULL DEFAULT now(),
+  closed_at timestamp without time zone,
+  opening_cash integer NOT NULL,
+  counted_cash integer,
+  counted_transfer integer,
+  note text,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT opening_cash_not_negative CHECK (opening_cash >= 0),
+  CONSTRAINT counted_when_closed CHECK (closed_at IS NULL OR (counted_cash IS NOT NULL AND counted_transfer IS NOT NULL))
+);
+
+-- Orders and payments registered while a duty is open belong to it
+ALTER TABLE orders ADD COLUMN duty_id integer CONSTRAINT duty_fk REFERENCES duties(id) ON DELETE SET NULL ON UPDATE CASCADE;
+ALTER TABLE payments ADD COLUMN duty_id integer CONSTRAINT duty_fk REFERENCES duties(id) ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN duties.opening_cash IS 'Cash in the box when the duty was opened';
+COMMENT ON COLUMN duties.counted_cash IS 'Cash in the box when the duty was closed';
+COMMENT ON COLUMN duties.counted_transfer IS 'Transfers received during the duty, checked when it was closed';
+
+-- Indices -------------------------------------------------------
+-- Only one duty can be open at a time
+CREATE UNIQUE INDEX duties_single_open_idx ON duties((closed_at IS NULL)) WHERE closed_at IS NULL;
+CREATE INDEX orders_duty_id_idx ON orders(duty_id int4_ops);
+CREATE INDEX payments_duty_id_idx ON payments(duty_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON duties
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+ALTER TABLE payments DROP COLUMN IF EXISTS duty_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS duty_id;
+DROP TABLE IF EXISTS duties;
@@ -0,0 +1,39 @@
+-- Cost ----------------------------------------------------------
+-- The current unit cost is kept on the candy, its changes are kept on candy_costs
+ALTER TABLE candies ADD COLUMN cost integer NOT NULL DEFAULT 0;
+ALTER TABLE candies ADD CONSTRAINT cost_not_negative CHECK (cost >= 0);
+
+-- Table Definition ----------------------------------------------
+CREATE TABLE candy_costs (
+  id SERIAL PRIMARY KEY,
+  candy_id integer NOT NULL CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  cost integer NOT NULL,
+  effective_from date NOT NULL DEFAULT CURRENT_DATE,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT cost_not_negative CHECK (cost >= 0)
+);
+
+-- The unit cost is copied into the item like the price, so later cost changes don't rewrite past margins.
+-- Items sold before costs were tracked have no known cost.
+ALTER TABLE order_items ADD COLUMN unit_cost integer NOT NULL DEFAULT 0;
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN candies.cost IS 'Current cost to make one unit';
+COMMENT ON TABLE candy_costs IS 'Every unit cost the candy had and since when';
+COMMENT ON COLUMN order_items.unit_cost IS 'Candy unit cost when it was sold';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX candy_costs_candy_id_effective_from_idx ON candy_costs(candy_id, effective_from);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON candy_costs
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_cost;
+DROP TABLE IF EXISTS candy_costs;
+ALTER TABLE candies DROP CONSTRAINT IF EXISTS cost_not_negative;
+ALTER TABLE candies DROP COLUMN IF EXISTS cost;
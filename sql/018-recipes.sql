@@ -0,0 +1,61 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE ingredients (
+  id SERIAL PRIMARY KEY,
+  name text NOT NULL,
+  unit text NOT NULL,
+  package_size numeric(12,3) NOT NULL,
+  package_price integer NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT package_size_positive CHECK (package_size > 0),
+  CONSTRAINT package_price_not_negative CHECK (package_price >= 0)
+);
+
+CREATE TABLE recipes (
+  candy_id integer PRIMARY KEY CONSTRAINT candy_fk REFERENCES candies(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  yield integer NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT yield_positive CHECK (yield > 0)
+);
+
+CREATE TABLE recipe_items (
+  id SERIAL PRIMARY KEY,
+  candy_id integer NOT NULL CONSTRAINT recipe_fk REFERENCES recipes(candy_id) ON DELETE CASCADE ON UPDATE CASCADE,
+  ingredient_id integer NOT NULL CONSTRAINT ingredient_fk REFERENCES ingredients(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+  quantity numeric(12,3) NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT quantity_positive CHECK (quantity > 0),
+  CONSTRAINT ingredient_once_per_recipe UNIQUE (candy_id, ingredient_id)
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN ingredients.unit IS 'Unit the package size and the recipe quantities are measured in, e.g. g, ml, un';
+COMMENT ON COLUMN ingredients.package_size IS 'How many units come in the package the ingredient is bought in';
+COMMENT ON COLUMN recipes.yield IS 'How many candies the recipe makes';
+
+-- Indices -------------------------------------------------------
+CREATE UNIQUE INDEX ingredients_name_idx ON ingredients(lower(name));
+CREATE INDEX recipe_items_ingredient_id_idx ON recipe_items(ingredient_id int4_ops);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON ingredients
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON recipes
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON recipe_items
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS recipe_items;
+DROP TABLE IF EXISTS recipes;
+DROP TABLE IF EXISTS ingredients;
@@ -0,0 +1,29 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE expenses (
+  id SERIAL PRIMARY KEY,
+  category text NOT NULL,
+  amount integer NOT NULL,
+  date date NOT NULL,
+  note text,
+  attachment_path text,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT amount_positive CHECK (amount > 0),
+  CONSTRAINT category_known CHECK (category IN ('packaging', 'gas', 'delivery', 'other'))
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN expenses.category IS 'packaging/gas/delivery/other';
+COMMENT ON COLUMN expenses.attachment_path IS 'Where the receipt is stored, if it was kept';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX expenses_date_id_idx ON expenses(date, id);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON expenses
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS expenses;
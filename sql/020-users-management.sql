@@ -0,0 +1,30 @@
+-- Active ----------------------------------------------------------
+-- Users are deactivated instead of deleted, so who did what stays known
+ALTER TABLE users ADD COLUMN active boolean NOT NULL DEFAULT true;
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN users.active IS 'Inactive users can no longer log in';
+
+-- Indices -------------------------------------------------------
+-- The e-mails become unique apart from their case, the users sharing one must be told apart by hand first
+DO $$
+DECLARE
+  duplicated text;
+BEGIN
+  SELECT string_agg(emails, '; ') INTO duplicated FROM (
+    SELECT string_agg(email || ' (id ' || id || ')', ', ' ORDER BY id) as emails
+    FROM users
+    GROUP BY lower(email)
+    HAVING COUNT(*) > 1
+  ) d;
+
+  IF duplicated IS NOT NULL THEN
+    RAISE EXCEPTION 'Some users share an e-mail apart from its case, change them before migrating: %', duplicated;
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX users_email_idx ON users(lower(email));
+
+-- migrate:down
+DROP INDEX IF EXISTS users_email_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS active;
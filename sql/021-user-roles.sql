@@ -0,0 +1,12 @@
+-- Role ------------------------------------------------------------
+-- The users that already exist could do everything, so they become owners. New users must be given a role.
+ALTER TABLE users ADD COLUMN role text NOT NULL DEFAULT 'owner';
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ADD CONSTRAINT role_known CHECK (role IN ('owner', 'seller', 'viewer'));
+
+-- Comments -------------------------------------------------------
+COMMENT ON COLUMN users.role IS 'owner/seller/viewer';
+
+-- migrate:down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS role_known;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
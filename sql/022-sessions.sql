@@ -0,0 +1,45 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE sessions (
+  id SERIAL PRIMARY KEY,
+  user_id integer NOT NULL CONSTRAINT user_fk REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  expires_at timestamp without time zone NOT NULL,
+  revoked_at timestamp without time zone,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+-- Every refresh token the session had. Only the last one is unused, the others were rotated.
+CREATE TABLE refresh_tokens (
+  id SERIAL PRIMARY KEY,
+  session_id integer NOT NULL CONSTRAINT session_fk REFERENCES sessions(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  token_hash text NOT NULL,
+  used_at timestamp without time zone,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON TABLE sessions IS 'A login, the access tokens carry its id and stop working once it is revoked';
+COMMENT ON COLUMN sessions.expires_at IS 'When its refresh tokens stop working, the session has to log in again';
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'SHA-256 of the refresh token, the token itself is only known by the client';
+COMMENT ON COLUMN refresh_tokens.used_at IS 'When it was exchanged, using it again means it leaked and revokes the session';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX sessions_user_id_idx ON sessions(user_id);
+CREATE UNIQUE INDEX refresh_tokens_token_hash_idx ON refresh_tokens(token_hash);
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens(session_id);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON sessions
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON refresh_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
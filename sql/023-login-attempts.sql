@@ -0,0 +1,30 @@
+-- Table Definition ----------------------------------------------
+CREATE TABLE login_attempts (
+  id SERIAL PRIMARY KEY,
+  email text NOT NULL,
+  ip text NOT NULL,
+  user_id integer CONSTRAINT user_fk REFERENCES users(id) ON DELETE SET NULL ON UPDATE CASCADE,
+  result text NOT NULL,
+  created_at timestamp without time zone NOT NULL DEFAULT now(),
+  updated_at timestamp without time zone NOT NULL DEFAULT now(),
+  CONSTRAINT result_known CHECK (result IN ('success', 'unknown_email', 'wrong_password', 'locked', 'pending'))
+);
+
+-- Comments -------------------------------------------------------
+COMMENT ON TABLE login_attempts IS 'Every login attempt, the failed ones lock the account and the IP for a while';
+COMMENT ON COLUMN login_attempts.email IS 'As typed, it may not belong to any user';
+COMMENT ON COLUMN login_attempts.result IS 'success/unknown_email/wrong_password/locked/pending, pending until the password is checked';
+
+-- Indices -------------------------------------------------------
+CREATE INDEX login_attempts_email_created_at_idx ON login_attempts(lower(email), created_at);
+CREATE INDEX login_attempts_ip_created_at_idx ON login_attempts(ip, created_at);
+CREATE INDEX login_attempts_created_at_id_idx ON login_attempts(created_at, id);
+
+-- Triggers -------------------------------------------------------
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON login_attempts
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_set_timestamp();
+
+-- migrate:down
+DROP TABLE IF EXISTS login_attempts;